@@ -0,0 +1,871 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// Balance holds every tunable chance and stat used by world generation and
+// combat. Maps are keyed by the display names of the types, e.g.
+// getPrintStringFromRoomType, getStringFromItemType and getEnemyNameFromType,
+// so a balance file reads the same as the game does.
+type Balance struct {
+	RoomChances       map[string]float64            // chance of a room type being generated
+	HowSticky         float64                       // chance added per matching adjacent room
+	WallChance        float64                       // chance of a wall between two rooms instead of a door
+	LockedDoorChance  float64                       // chance of a door being locked
+	ChestCounts       map[string][]float64          // chance of 0, 1, 2, ... chests per room type
+	ChestLockedChance float64                       // chance of a chest being locked
+	ChestGold         GoldRoll                      // gold found in a chest along with its item
+	ItemChances       map[string]float64            // chance of a chest holding each item type
+	ItemTiers         map[string][]ItemTier         // effect tiers per item type
+	LootTables        map[string]map[string]float64 // item chances per room type, ItemChances for room types left out
+	RarityChances     map[string][]float64          // chance of each rarity of equipment, Common first, per room type
+	Affixes           []AffixStats                  // bonuses equipment can roll, more the rarer it is
+	InventorySize     int                           // inventory slots the player starts with
+	MaxInventorySize  int                           // inventory slots bags can add up to
+	StackSizes        map[string]int                // items of a type that fit in one slot, 1 if left out
+	ItemWeights       map[string]float64            // weight of one item of each type
+	CarryWeight       float64                       // weight the player can carry, equipped armor included
+	EnemySpawns       map[string][]EnemySpawn       // enemy groups per room type
+	RunFromChances    map[string]float64            // chance of getting out of a room type
+	RunToChances      map[string]float64            // chance of getting into a room type
+	StartingMoves     []MoveStats                   // moves the player starts with in their loadout
+	LearnableMoves    []MoveStats                   // moves learned at shrines and on level up
+	LoadoutSize       int                           // moves the player can fight with at once
+	LevelsPerMove     int                           // a move is learned every this many levels, 0 for never
+	EnemyXP           map[string]float64            // xp for defeating each enemy type
+	EnemyGold         map[string]int                // gold dropped by each enemy type
+	XPPerLevel        float64                       // xp for level n to n+1 is XPPerLevel * n
+	HealthPerLevel    float64                       // max health gained per level
+	StrengthPerLevel  float64
+	DefensePerLevel   float64
+	ShrineStatuses    []StatusChance        // blessings and curses of Mystical Room shrines
+	RestHeal          float64               // share of max health resting in a Great Hall heals
+	PrisonerXP        float64               // xp for freeing the prisoners of a Dungeon
+	MerchantRings     int                   // a Merchant is placed on every this many rings, 0 for none
+	MerchantStock     int                   // items a Merchant has for sale
+	MerchantBuyBack   int                   // items sold to a Merchant that can be bought back
+	ItemPrices        map[string]float64    // price of the first tier of each item type
+	TierPriceScale    float64               // each tier above the first adds this share of the price
+	RarityPriceScales []float64             // price multiplier of each rarity, Common first
+	SellShare         float64               // share of the price a Merchant pays for an item
+	Difficulty        string                // the entry of Difficulties the world is generated with
+	Difficulties      map[string]Difficulty // difficulty presets by name
+}
+
+type ItemTier struct {
+	Chance      float64
+	Effect      float64
+	Name        string  `json:",omitempty"` // what items of the tier are called, the item type if left out
+	Status      string  `json:",omitempty"` // getStringFromStatusType, put on by using the item
+	StatusPower float64 `json:",omitempty"`
+	StatusTurns int     `json:",omitempty"`
+}
+
+// AffixStats is one affix equipment can roll, its power is rolled between Min
+// and Max. Names of prefixes go before the item name and suffixes after it.
+type AffixStats struct {
+	Name   string
+	Suffix bool   `json:",omitempty"`
+	Stat   string // one of getAllEquipStats
+	Min    float64
+	Max    float64
+	Items  []string // getStringFromItemType of the equipment that can roll it
+}
+
+// Difficulty is how much harder the world gets away from the start room. The
+// depth of ring r is PerRing * r^Exponent, capped at MaxDepth, and everything
+// below scales with it. The Shift settings move chance toward the later entries
+// of ChestCounts, ItemTiers and EnemySpawns, so those lists go from the easiest
+// to the hardest entry: entry i of n is weighted by (1 + Shift*depth)^(i/(n-1)).
+type Difficulty struct {
+	PerRing       float64
+	Exponent      float64
+	MaxDepth      float64
+	EnemyHealth   float64 // enemy health is multiplied by 1 + EnemyHealth*depth
+	EnemyStrength float64 // enemy strength is multiplied by 1 + EnemyStrength*depth
+	SpawnShift    float64 // toward the later EnemySpawns groups
+	ChestShift    float64 // toward more chests
+	TierShift     float64 // toward the later ItemTiers
+	RarityShift   float64 // toward the rarer RarityChances
+}
+
+// StatusChance is one status that may be put on, see Status.
+type StatusChance struct {
+	Chance float64
+	Status string // getStringFromStatusType
+	Power  float64
+	Turns  int
+}
+
+// EnemySpawn is one possible group of enemies for a room, an empty group means
+// the room has no enemies.
+type EnemySpawn struct {
+	Chance  float64
+	Enemies []string
+}
+
+type MoveStats struct {
+	Name        string
+	MinDamage   float64
+	MaxDamage   float64
+	Cooldown    int32
+	HitsAll     bool
+	Hits        int     `json:",omitempty"` // times each target is hit, 0 means once
+	Effect      string  `json:",omitempty"` // getStringFromMoveEffect
+	EffectPower float64 `json:",omitempty"`
+	EffectTurns int     `json:",omitempty"`
+}
+
+// how far off a set of chances can be from summing to 1
+const balanceEpsilon = 1e-6
+
+func defaultBalance() *Balance {
+	b := new(Balance)
+	b.RoomChances = map[string]float64{
+		"Start Room":    0,
+		"Hallway":       0.5,
+		"Great Hall":    0.2,
+		"Dungeon":       0.15,
+		"Chest Room":    0.1,
+		"Mystical Room": 0.05,
+	}
+	b.HowSticky = 0.25
+	b.WallChance = 0.2
+	b.LockedDoorChance = 0.1
+	b.ChestCounts = map[string][]float64{
+		"Start Room":    {1},
+		"Hallway":       {.85, .15},
+		"Great Hall":    {.65, .3, .05},
+		"Dungeon":       {.625, .3, .075},
+		"Chest Room":    {0, .25, .5, .25},
+		"Mystical Room": {.05, .35, .45, .15},
+	}
+	b.ChestLockedChance = 0.4
+	b.ChestGold = GoldRoll{Chance: 0.5, Min: 5, Max: 20}
+	b.ItemChances = map[string]float64{
+		"Key":    0.40,
+		"Armor":  0.10,
+		"Health": 0.20,
+		"Damage": 0.12,
+		"Bag":    0.04,
+		"Weapon": 0.06,
+		"Helmet": 0.04,
+		"Ring":   0.02,
+		"Amulet": 0.02,
+	}
+	b.ItemTiers = map[string][]ItemTier{
+		"Key": {
+			{Chance: .6, Effect: 1, Name: "Rusty Key"},
+			{Chance: .3, Effect: 2, Name: "Iron Key"},
+			{Chance: .1, Effect: 3, Name: "Master Key"},
+		},
+		"Armor": {
+			{Chance: .4, Effect: 1, Name: "Leather Armor"},
+			{Chance: .3, Effect: 2, Name: "Chainmail"},
+			{Chance: .2, Effect: 3, Name: "Scale Mail"},
+			{Chance: .1, Effect: 4, Name: "Plate Armor"},
+		},
+		"Health": {
+			{Chance: .5, Effect: 20, Name: "Small Health Potion"},
+			{Chance: .35, Effect: 50, Name: "Health Potion"},
+			{Chance: .1, Effect: 100, Name: "Large Health Potion", Status: "Regen", StatusPower: 5, StatusTurns: 3},
+			{Chance: .05, Effect: 200, Name: "Elixir", Status: "Regen", StatusPower: 10, StatusTurns: 3},
+		},
+		"Damage": {
+			{Chance: .525, Effect: 20, Name: "Firecracker"},
+			{Chance: .375, Effect: 50, Name: "Bomb"},
+			{Chance: .075, Effect: 100, Name: "Venom Bomb", Status: "Poison", StatusPower: 5, StatusTurns: 3},
+			{Chance: .025, Effect: 200, Name: "Plague Keg", Status: "Poison", StatusPower: 10, StatusTurns: 3},
+		},
+		"Bag": {
+			{Chance: .7, Effect: 2, Name: "Pouch"},
+			{Chance: .3, Effect: 4, Name: "Backpack"},
+		},
+		"Weapon": {
+			{Chance: .5, Effect: 1, Name: "Dagger"},
+			{Chance: .3, Effect: 2, Name: "Sword"},
+			{Chance: .15, Effect: 4, Name: "Battle Axe"},
+			{Chance: .05, Effect: 6, Name: "Warhammer"},
+		},
+		"Helmet": {
+			{Chance: .5, Effect: .5, Name: "Leather Cap"},
+			{Chance: .35, Effect: 1, Name: "Iron Helm"},
+			{Chance: .15, Effect: 2, Name: "Great Helm"},
+		},
+		"Ring": {
+			{Chance: .6, Effect: .1, Name: "Copper Ring"},
+			{Chance: .3, Effect: .2, Name: "Silver Ring"},
+			{Chance: .1, Effect: .35, Name: "Gold Ring"},
+		},
+		"Amulet": {
+			{Chance: .6, Effect: 10, Name: "Charm"},
+			{Chance: .3, Effect: 25, Name: "Amulet"},
+			{Chance: .1, Effect: 50, Name: "Talisman"},
+		},
+	}
+	b.LootTables = map[string]map[string]float64{
+		// chest rooms are the armories of the castle
+		"Chest Room": {
+			"Key": 0.40, "Armor": 0.14, "Health": 0.12, "Damage": 0.08, "Bag": 0.04,
+			"Weapon": 0.10, "Helmet": 0.08, "Ring": 0.02, "Amulet": 0.02,
+		},
+		// mystics keep their trinkets
+		"Mystical Room": {
+			"Key": 0.40, "Armor": 0.04, "Health": 0.20, "Damage": 0.08, "Bag": 0.04,
+			"Weapon": 0.02, "Helmet": 0.02, "Ring": 0.10, "Amulet": 0.10,
+		},
+		// the guards took the good gear, but not their keys
+		"Dungeon": {
+			"Key": 0.55, "Armor": 0.08, "Health": 0.15, "Damage": 0.10, "Bag": 0.04,
+			"Weapon": 0.04, "Helmet": 0.02, "Ring": 0.01, "Amulet": 0.01,
+		},
+		// merchants sell a bit of everything
+		"Merchant Room": {
+			"Key": 0.40, "Armor": 0.08, "Health": 0.14, "Damage": 0.08, "Bag": 0.06,
+			"Weapon": 0.10, "Helmet": 0.06, "Ring": 0.04, "Amulet": 0.04,
+		},
+	}
+	b.RarityChances = map[string][]float64{
+		"Start Room":    {1},
+		"Hallway":       {.7, .22, .06, .02},
+		"Great Hall":    {.6, .25, .1, .04, .01},
+		"Dungeon":       {.65, .23, .08, .03, .01},
+		"Chest Room":    {.45, .3, .15, .07, .03},
+		"Mystical Room": {.5, .25, .13, .08, .04},
+		"Merchant Room": {.4, .3, .17, .09, .04},
+	}
+	b.Affixes = []AffixStats{
+		{Name: "Sharp", Stat: "Damage", Min: 0.5, Max: 2, Items: []string{"Weapon"}},
+		{Name: "Sturdy", Stat: "Defense", Min: 0.25, Max: 1, Items: []string{"Armor", "Helmet"}},
+		{Name: "Hearty", Stat: "Max Health", Min: 5, Max: 15, Items: []string{"Armor", "Helmet", "Amulet"}},
+		{Name: "Vampiric", Stat: "Lifesteal", Min: 0.03, Max: 0.1, Items: []string{"Weapon", "Ring", "Amulet"}},
+		{Name: "of the Brute", Suffix: true, Stat: "Strength", Min: 0.05, Max: 0.15, Items: []string{"Weapon", "Ring", "Amulet"}},
+		{Name: "of Warding", Suffix: true, Stat: "Defense", Min: 0.25, Max: 1, Items: []string{"Helmet", "Ring", "Amulet"}},
+		{Name: "of the Bear", Suffix: true, Stat: "Max Health", Min: 10, Max: 25, Items: []string{"Armor", "Amulet"}},
+		{Name: "of Fury", Suffix: true, Stat: "Damage", Min: 0.5, Max: 1.5, Items: []string{"Weapon", "Ring"}},
+	}
+	b.InventorySize = 10
+	b.MaxInventorySize = 20
+	b.StackSizes = map[string]int{
+		"Key":    5,
+		"Health": 5,
+		"Damage": 5,
+	}
+	b.ItemWeights = map[string]float64{
+		"Key":    0.1,
+		"Armor":  5,
+		"Health": 0.5,
+		"Damage": 1,
+		"Bag":    0.5,
+		"Weapon": 4,
+		"Helmet": 2,
+		"Ring":   0.1,
+		"Amulet": 0.2,
+	}
+	b.CarryWeight = 40
+	b.EnemySpawns = map[string][]EnemySpawn{
+		"Start Room": {},
+		"Hallway": {
+			{.7, nil},
+			{.25, []string{"Peon"}},
+			{.05, []string{"Warrior"}},
+		},
+		"Great Hall": {
+			{.6, nil},
+			{.1, []string{"Peon"}},
+			{.3, []string{"Warrior"}},
+		},
+		"Dungeon": {
+			{.025, []string{"Peon"}},
+			{.025, []string{"Peon", "Peon"}},
+			{.05, []string{"Warrior"}},
+			{.15, []string{"Warrior", "Warrior"}},
+			{.65, []string{"Brute"}},
+			{.1, []string{"Brute", "Brute"}},
+		},
+		"Chest Room": {
+			{.4, nil},
+			{.4, []string{"Warrior"}},
+			{.2, []string{"Brute"}},
+		},
+		"Mystical Room": {
+			{.3, nil},
+			{.2, []string{"Warrior"}},
+			{.25, []string{"Mystic"}},
+			{.25, []string{"Mystic", "Mystic"}},
+		},
+	}
+	b.RunFromChances = map[string]float64{
+		"Start Room":    1,
+		"Hallway":       .7,
+		"Great Hall":    .6,
+		"Dungeon":       .2,
+		"Chest Room":    .4,
+		"Mystical Room": .3,
+		"Boss Room":     .15, // the Warlord rarely lets anyone go
+		"Merchant Room": 1,
+	}
+	b.RunToChances = map[string]float64{
+		"Start Room":    1,
+		"Hallway":       1,
+		"Great Hall":    .9,
+		"Dungeon":       .3,
+		"Chest Room":    .7,
+		"Mystical Room": .6,
+		"Boss Room":     .5,
+		"Merchant Room": 1, // merchants keep their door open
+	}
+	b.StartingMoves = []MoveStats{
+		{Name: "Punch", MinDamage: 3.0, MaxDamage: 6.0},
+		{Name: "Kick", MinDamage: 5.0, MaxDamage: 10.0},
+		{Name: "Special", MinDamage: 9.0, MaxDamage: 15.0, Cooldown: 3},
+		{Name: "Sweep", MinDamage: 2.0, MaxDamage: 5.0, Cooldown: 2, HitsAll: true},
+	}
+	b.LearnableMoves = []MoveStats{
+		{Name: "Stunning Blow", MinDamage: 4.0, MaxDamage: 8.0, Cooldown: 3, Effect: "Stun", EffectTurns: 1},
+		{Name: "Rend", MinDamage: 3.0, MaxDamage: 6.0, Cooldown: 2, Effect: "Bleed", EffectPower: 4.0, EffectTurns: 3},
+		{Name: "Sunder", MinDamage: 4.0, MaxDamage: 7.0, Cooldown: 3, Effect: "Armor Break", EffectPower: 3.0, EffectTurns: 3},
+		{Name: "Second Wind", Cooldown: 4, Effect: "Heal", EffectPower: 25.0},
+		{Name: "Flurry", MinDamage: 2.0, MaxDamage: 4.0, Cooldown: 2, Hits: 3},
+		{Name: "Venom Strike", MinDamage: 2.0, MaxDamage: 5.0, Cooldown: 2, Effect: "Poison", EffectPower: 3.0, EffectTurns: 4},
+		{Name: "Crippling Blow", MinDamage: 3.0, MaxDamage: 6.0, Cooldown: 3, Effect: "Weaken", EffectPower: 0.3, EffectTurns: 2},
+		{Name: "Guard", Cooldown: 3, Effect: "Shield", EffectPower: 5.0, EffectTurns: 2},
+		{Name: "Meditate", Cooldown: 5, Effect: "Regen", EffectPower: 6.0, EffectTurns: 4},
+	}
+	b.LoadoutSize = 4
+	b.LevelsPerMove = 2
+	b.EnemyXP = map[string]float64{
+		"Peon":    10,
+		"Warrior": 20,
+		"Brute":   35,
+		"Mystic":  25,
+		"Warlord": 200,
+	}
+	b.EnemyGold = map[string]int{
+		"Peon":    3,
+		"Warrior": 6,
+		"Brute":   10,
+		"Mystic":  8,
+		"Warlord": 100,
+	}
+	b.XPPerLevel = 50
+	b.HealthPerLevel = 10
+	b.StrengthPerLevel = 0.1
+	b.DefensePerLevel = 0.5
+	b.ShrineStatuses = []StatusChance{
+		{.35, "Regen", 5, 5},
+		{.35, "Shielded", 3, 5},
+		{.15, "Weakened", 0.25, 3},
+		{.15, "Poison", 3, 3},
+	}
+	b.RestHeal = 0.5
+	b.PrisonerXP = 15
+	b.MerchantRings = 5
+	b.MerchantStock = 6
+	b.MerchantBuyBack = 5
+	b.ItemPrices = map[string]float64{
+		"Key":    15,
+		"Armor":  30,
+		"Health": 10,
+		"Damage": 12,
+		"Bag":    60,
+		"Weapon": 35,
+		"Helmet": 25,
+		"Ring":   40,
+		"Amulet": 40,
+	}
+	b.TierPriceScale = 0.75
+	b.RarityPriceScales = []float64{1, 1.5, 2.5, 4, 7}
+	b.SellShare = 0.4
+	b.Difficulty = "Normal"
+	b.Difficulties = map[string]Difficulty{
+		"Easy":   {PerRing: 0.03, Exponent: 1, MaxDepth: 2, EnemyHealth: 0.3, EnemyStrength: 0.15, SpawnShift: 0.5, ChestShift: 1, TierShift: 1.5, RarityShift: 1.5},
+		"Normal": {PerRing: 0.05, Exponent: 1, MaxDepth: 3, EnemyHealth: 0.5, EnemyStrength: 0.25, SpawnShift: 1, ChestShift: 0.5, TierShift: 1, RarityShift: 1},
+		"Hard":   {PerRing: 0.08, Exponent: 1.1, MaxDepth: 4, EnemyHealth: 0.75, EnemyStrength: 0.4, SpawnShift: 2, ChestShift: 0.25, TierShift: 0.75, RarityShift: 0.75},
+	}
+	return b
+}
+
+// loadBalance reads a balance file. Anything left out of the file keeps its
+// built in default.
+func loadBalance(r io.Reader) (*Balance, error) {
+	b := defaultBalance()
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(b); err != nil {
+		return nil, err
+	}
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func loadBalanceFromFile(path string) (*Balance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return loadBalance(f)
+}
+
+func (b *Balance) write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(b)
+}
+
+func checkChance(name string, chance float64) error {
+	if chance < 0 || chance > 1 || math.IsNaN(chance) {
+		return fmt.Errorf("balance: %s must be between 0 and 1, got %v", name, chance)
+	}
+	return nil
+}
+
+// checkItemChances checks a table of item chances, ItemChances or one of
+// LootTables. Every item type it can roll needs ItemTiers.
+func (b *Balance) checkItemChances(name string, chances map[string]float64, itemNames map[string]bool) error {
+	sum := 0.0
+	for item, chance := range chances {
+		if !itemNames[item] {
+			return fmt.Errorf("balance: unknown item type %q in %s", item, name)
+		}
+		if err := checkChance(name+" "+item, chance); err != nil {
+			return err
+		}
+		sum += chance
+		if chance > 0 && len(b.ItemTiers[item]) == 0 {
+			return fmt.Errorf("balance: item type %q can be generated but has no ItemTiers", item)
+		}
+	}
+	if err := checkSum(name, sum); err != nil {
+		return err
+	}
+	// so all locked chests can be opened
+	if chances[getStringFromItemType(KEY)] < b.ChestLockedChance {
+		return fmt.Errorf("balance: the Key chance (%v) of %s must be at least ChestLockedChance (%v)", chances[getStringFromItemType(KEY)], name, b.ChestLockedChance)
+	}
+	return nil
+}
+
+// checkRarityChances checks the RarityChances of the room type name.
+func checkRarityChances(name string, chances []float64) error {
+	if len(chances) == 0 || len(chances) > len(getAllRarities()) {
+		return fmt.Errorf("balance: RarityChances %s must have 1 to %d entries, got %d", name, len(getAllRarities()), len(chances))
+	}
+	sum := 0.0
+	for _, chance := range chances {
+		if err := checkChance("RarityChances "+name, chance); err != nil {
+			return err
+		}
+		sum += chance
+	}
+	return checkSum("RarityChances "+name, sum)
+}
+
+func checkSum(name string, sum float64) error {
+	if math.Abs(sum-1) > balanceEpsilon {
+		return fmt.Errorf("balance: %s must sum to 1, got %v", name, sum)
+	}
+	return nil
+}
+
+func (b *Balance) validate() error {
+	roomNames := make(map[string]bool)
+	for _, rType := range getGenetateableTypes() {
+		roomNames[getPrintStringFromRoomType(rType)] = true
+	}
+	// rooms that are placed instead of generated can still have their own loot
+	lootRoomNames := make(map[string]bool)
+	for _, rType := range getAllRoomTypes() {
+		lootRoomNames[getPrintStringFromRoomType(rType)] = true
+	}
+	itemNames := make(map[string]bool)
+	for _, iType := range getAllItemTypes() {
+		itemNames[getStringFromItemType(iType)] = true
+	}
+	enemyNames := make(map[string]bool)
+	for _, eType := range getAllEnemyTypes() {
+		enemyNames[getEnemyNameFromType(eType)] = true
+	}
+
+	sum := 0.0
+	for name, chance := range b.RoomChances {
+		if !roomNames[name] {
+			return fmt.Errorf("balance: unknown room type %q in RoomChances", name)
+		}
+		if err := checkChance("RoomChances "+name, chance); err != nil {
+			return err
+		}
+		sum += chance
+	}
+	if err := checkSum("RoomChances", sum); err != nil {
+		return err
+	}
+
+	// four matching neighbours must not add up to more than a certain room
+	if b.HowSticky < 0 || b.HowSticky > 0.25 {
+		return fmt.Errorf("balance: HowSticky must be between 0 and 0.25, got %v", b.HowSticky)
+	}
+
+	if err := checkChance("WallChance", b.WallChance); err != nil {
+		return err
+	}
+	if err := checkChance("LockedDoorChance", b.LockedDoorChance); err != nil {
+		return err
+	}
+
+	if err := checkChance("ChestLockedChance", b.ChestLockedChance); err != nil {
+		return err
+	}
+	if err := checkChance("ChestGold Chance", b.ChestGold.Chance); err != nil {
+		return err
+	}
+	if b.ChestGold.Min < 0 || b.ChestGold.Max < b.ChestGold.Min {
+		return fmt.Errorf("balance: ChestGold must have 0 <= Min <= Max, got %d and %d", b.ChestGold.Min, b.ChestGold.Max)
+	}
+
+	sum = 0.0
+	if err := b.checkItemChances("ItemChances", b.ItemChances, itemNames); err != nil {
+		return err
+	}
+
+	for name, tiers := range b.ItemTiers {
+		if !itemNames[name] {
+			return fmt.Errorf("balance: unknown item type %q in ItemTiers", name)
+		}
+		sum = 0.0
+		for _, tier := range tiers {
+			if err := checkChance("ItemTiers "+name, tier.Chance); err != nil {
+				return err
+			}
+			sum += tier.Chance
+		}
+		if err := checkSum("ItemTiers "+name, sum); err != nil {
+			return err
+		}
+		for _, tier := range tiers {
+			if tier.Status == "" {
+				continue
+			}
+			if err := checkStatus("ItemTiers "+name, tier.Status, tier.StatusPower, tier.StatusTurns); err != nil {
+				return err
+			}
+		}
+	}
+	for name, table := range b.LootTables {
+		if !lootRoomNames[name] {
+			return fmt.Errorf("balance: unknown room type %q in LootTables", name)
+		}
+		if err := b.checkItemChances("LootTables "+name, table, itemNames); err != nil {
+			return err
+		}
+	}
+	for _, rType := range getGenetateableTypes() {
+		name := getPrintStringFromRoomType(rType)
+		chances, ok := b.RarityChances[name]
+		if !ok {
+			return fmt.Errorf("balance: room type %q is missing from RarityChances", name)
+		}
+		if err := checkRarityChances(name, chances); err != nil {
+			return err
+		}
+	}
+	for name, chances := range b.RarityChances {
+		if !lootRoomNames[name] {
+			return fmt.Errorf("balance: unknown room type %q in RarityChances", name)
+		}
+		// the generated room types were checked above
+		if roomNames[name] {
+			continue
+		}
+		if err := checkRarityChances(name, chances); err != nil {
+			return err
+		}
+	}
+	for _, affix := range b.Affixes {
+		if affix.Name == "" {
+			return fmt.Errorf("balance: every affix needs a Name")
+		}
+		if !isEquipStat(affix.Stat) {
+			return fmt.Errorf("balance: unknown stat %q of affix %q", affix.Stat, affix.Name)
+		}
+		if affix.Min < 0 || affix.Max < affix.Min {
+			return fmt.Errorf("balance: affix %q must have 0 <= Min <= Max, got %v and %v", affix.Name, affix.Min, affix.Max)
+		}
+		for _, item := range affix.Items {
+			if iType := getItemTypeFromString(item); iType == -1 || !isEquipType(iType) {
+				return fmt.Errorf("balance: affix %q can only be rolled by equipment, got %q", affix.Name, item)
+			}
+		}
+	}
+
+	if b.InventorySize < 1 {
+		return fmt.Errorf("balance: InventorySize must be at least 1, got %d", b.InventorySize)
+	}
+	if b.MaxInventorySize < b.InventorySize {
+		return fmt.Errorf("balance: MaxInventorySize (%d) must be at least InventorySize (%d)", b.MaxInventorySize, b.InventorySize)
+	}
+	for name, size := range b.StackSizes {
+		if !itemNames[name] {
+			return fmt.Errorf("balance: unknown item type %q in StackSizes", name)
+		}
+		if size < 1 {
+			return fmt.Errorf("balance: StackSizes %s must be at least 1, got %d", name, size)
+		}
+	}
+	// equipment is worn one at a time, swapping it out of a stack would split it
+	for _, iType := range getEquipTypes() {
+		if b.StackSizes[getStringFromItemType(iType)] > 1 {
+			return fmt.Errorf("balance: %s cannot stack", getStringFromItemType(iType))
+		}
+	}
+	for name, weight := range b.ItemWeights {
+		if !itemNames[name] {
+			return fmt.Errorf("balance: unknown item type %q in ItemWeights", name)
+		}
+		if weight < 0 {
+			return fmt.Errorf("balance: ItemWeights %s must not be negative, got %v", name, weight)
+		}
+	}
+	if b.CarryWeight <= 0 {
+		return fmt.Errorf("balance: CarryWeight must be above 0, got %v", b.CarryWeight)
+	}
+
+	for _, rType := range getGenetateableTypes() {
+		name := getPrintStringFromRoomType(rType)
+		counts, ok := b.ChestCounts[name]
+		if !ok {
+			return fmt.Errorf("balance: room type %q is missing from ChestCounts", name)
+		}
+		sum = 0.0
+		for _, chance := range counts {
+			if err := checkChance("ChestCounts "+name, chance); err != nil {
+				return err
+			}
+			sum += chance
+		}
+		if err := checkSum("ChestCounts "+name, sum); err != nil {
+			return err
+		}
+
+		spawns, ok := b.EnemySpawns[name]
+		if !ok {
+			return fmt.Errorf("balance: room type %q is missing from EnemySpawns", name)
+		}
+		sum = 0.0
+		for _, spawn := range spawns {
+			if err := checkChance("EnemySpawns "+name, spawn.Chance); err != nil {
+				return err
+			}
+			for _, enemy := range spawn.Enemies {
+				if !enemyNames[enemy] {
+					return fmt.Errorf("balance: unknown enemy type %q in EnemySpawns %s", enemy, name)
+				}
+				if enemy == getEnemyNameFromType(WARLORD) {
+					return fmt.Errorf("balance: the %s only guards the %s, it cannot be in EnemySpawns %s", enemy, getPrintStringFromRoomType(BOSS), name)
+				}
+			}
+			sum += spawn.Chance
+		}
+		// a room without any spawns simply never has enemies
+		if len(spawns) > 0 {
+			if err := checkSum("EnemySpawns "+name, sum); err != nil {
+				return err
+			}
+		}
+
+	}
+	// fights can happen in placed rooms too, a missing entry would mean running
+	// never works
+	for _, rType := range getAllRoomTypes() {
+		name := getPrintStringFromRoomType(rType)
+		if _, ok := b.RunFromChances[name]; !ok {
+			return fmt.Errorf("balance: room type %q is missing from RunFromChances", name)
+		}
+		if err := checkChance("RunFromChances "+name, b.RunFromChances[name]); err != nil {
+			return err
+		}
+		if _, ok := b.RunToChances[name]; !ok {
+			return fmt.Errorf("balance: room type %q is missing from RunToChances", name)
+		}
+		if err := checkChance("RunToChances "+name, b.RunToChances[name]); err != nil {
+			return err
+		}
+	}
+	for name := range b.ChestCounts {
+		if !roomNames[name] {
+			return fmt.Errorf("balance: unknown room type %q in ChestCounts", name)
+		}
+	}
+	for name := range b.EnemySpawns {
+		if !roomNames[name] {
+			return fmt.Errorf("balance: unknown room type %q in EnemySpawns", name)
+		}
+	}
+
+	for _, eType := range getAllEnemyTypes() {
+		if _, ok := b.EnemyXP[getEnemyNameFromType(eType)]; !ok {
+			return fmt.Errorf("balance: enemy type %q is missing from EnemyXP", getEnemyNameFromType(eType))
+		}
+	}
+	for name, xp := range b.EnemyXP {
+		if !enemyNames[name] {
+			return fmt.Errorf("balance: unknown enemy type %q in EnemyXP", name)
+		}
+		if xp < 0 {
+			return fmt.Errorf("balance: EnemyXP %s must not be negative, got %v", name, xp)
+		}
+	}
+	for _, eType := range getAllEnemyTypes() {
+		if _, ok := b.EnemyGold[getEnemyNameFromType(eType)]; !ok {
+			return fmt.Errorf("balance: enemy type %q is missing from EnemyGold", getEnemyNameFromType(eType))
+		}
+	}
+	for name, gold := range b.EnemyGold {
+		if !enemyNames[name] {
+			return fmt.Errorf("balance: unknown enemy type %q in EnemyGold", name)
+		}
+		if gold < 0 {
+			return fmt.Errorf("balance: EnemyGold %s must not be negative, got %d", name, gold)
+		}
+	}
+	if b.XPPerLevel <= 0 {
+		return fmt.Errorf("balance: XPPerLevel must be above 0, got %v", b.XPPerLevel)
+	}
+	if b.HealthPerLevel < 0 || b.StrengthPerLevel < 0 || b.DefensePerLevel < 0 {
+		return fmt.Errorf("balance: HealthPerLevel, StrengthPerLevel and DefensePerLevel must not be negative")
+	}
+
+	if len(b.StartingMoves) == 0 {
+		return fmt.Errorf("balance: there must be at least one starting move")
+	}
+	if b.LoadoutSize < len(b.StartingMoves) {
+		return fmt.Errorf("balance: LoadoutSize (%d) must fit all %d starting moves", b.LoadoutSize, len(b.StartingMoves))
+	}
+	if b.LevelsPerMove < 0 {
+		return fmt.Errorf("balance: LevelsPerMove must not be negative, got %d", b.LevelsPerMove)
+	}
+	moveNames := make(map[string]bool)
+	for _, move := range append(append([]MoveStats(nil), b.StartingMoves...), b.LearnableMoves...) {
+		if err := checkMove(move); err != nil {
+			return err
+		}
+		if moveNames[move.Name] {
+			return fmt.Errorf("balance: there are two moves named %q", move.Name)
+		}
+		moveNames[move.Name] = true
+	}
+	// Move.id is a uint8
+	if len(b.StartingMoves)+len(b.LearnableMoves) > math.MaxUint8+1 {
+		return fmt.Errorf("balance: there can be at most %d moves", math.MaxUint8+1)
+	}
+
+	if err := checkChance("RestHeal", b.RestHeal); err != nil {
+		return err
+	}
+	if b.PrisonerXP < 0 {
+		return fmt.Errorf("balance: PrisonerXP must not be negative, got %v", b.PrisonerXP)
+	}
+
+	if b.MerchantRings < 0 || b.MerchantStock < 0 || b.MerchantBuyBack < 0 {
+		return fmt.Errorf("balance: MerchantRings, MerchantStock and MerchantBuyBack must not be negative")
+	}
+	for name, price := range b.ItemPrices {
+		if !itemNames[name] {
+			return fmt.Errorf("balance: unknown item type %q in ItemPrices", name)
+		}
+		if price < 0 {
+			return fmt.Errorf("balance: ItemPrices %s must not be negative, got %v", name, price)
+		}
+	}
+	if b.TierPriceScale < 0 {
+		return fmt.Errorf("balance: TierPriceScale must not be negative, got %v", b.TierPriceScale)
+	}
+	if len(b.RarityPriceScales) != len(getAllRarities()) {
+		return fmt.Errorf("balance: RarityPriceScales must have %d entries, got %d", len(getAllRarities()), len(b.RarityPriceScales))
+	}
+	for _, scale := range b.RarityPriceScales {
+		if scale < 0 {
+			return fmt.Errorf("balance: RarityPriceScales must not be negative, got %v", scale)
+		}
+	}
+	if err := checkChance("SellShare", b.SellShare); err != nil {
+		return err
+	}
+
+	if _, ok := b.Difficulties[b.Difficulty]; !ok {
+		return fmt.Errorf("balance: Difficulty %q is not one of the Difficulties", b.Difficulty)
+	}
+	for name, difficulty := range b.Difficulties {
+		if difficulty.PerRing < 0 || difficulty.Exponent <= 0 || difficulty.MaxDepth < 0 {
+			return fmt.Errorf("balance: difficulty %q needs a PerRing and MaxDepth of at least 0 and an Exponent above 0", name)
+		}
+		if difficulty.EnemyHealth < 0 || difficulty.EnemyStrength < 0 || difficulty.SpawnShift < 0 || difficulty.ChestShift < 0 || difficulty.TierShift < 0 || difficulty.RarityShift < 0 {
+			return fmt.Errorf("balance: the scaling of difficulty %q must not be negative", name)
+		}
+	}
+
+	// no statuses means shrines only teach moves
+	if len(b.ShrineStatuses) > 0 {
+		sum = 0.0
+		for _, status := range b.ShrineStatuses {
+			if err := checkChance("ShrineStatuses "+status.Status, status.Chance); err != nil {
+				return err
+			}
+			if err := checkStatus("ShrineStatuses", status.Status, status.Power, status.Turns); err != nil {
+				return err
+			}
+			sum += status.Chance
+		}
+		if err := checkSum("ShrineStatuses", sum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkStatus(name, status string, power float64, turns int) error {
+	if getStatusTypeFromString(status) == -1 {
+		return fmt.Errorf("balance: unknown status %q in %s", status, name)
+	}
+	if power < 0 || turns < 1 {
+		return fmt.Errorf("balance: status %q in %s needs a power of at least 0 and at least 1 turn", status, name)
+	}
+	return nil
+}
+
+func checkMove(move MoveStats) error {
+	if move.MinDamage < 0 || move.MinDamage > move.MaxDamage || move.Cooldown < 0 || move.Hits < 0 {
+		return fmt.Errorf("balance: move %q has invalid stats", move.Name)
+	}
+	effect := getMoveEffectFromString(move.Effect)
+	if effect == -1 {
+		return fmt.Errorf("balance: move %q has unknown effect %q", move.Name, move.Effect)
+	}
+	if effect != MOVE_NONE && (move.EffectPower < 0 || move.EffectTurns < 0) {
+		return fmt.Errorf("balance: move %q has invalid effect stats", move.Name)
+	}
+	return nil
+}
+
+// rollChance picks an index from a list of chances that sum to 1.
+func rollChance(rng *rand.Rand, chances []float64) int {
+	chanceNeeded := rng.Float64()
+	chance := 0.0
+	for i, val := range chances {
+		chance += val
+		if chance > chanceNeeded {
+			return i
+		}
+	}
+	// only reachable through rounding
+	return len(chances) - 1
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultBalanceIsValid(t *testing.T) {
+	if err := defaultBalance().validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBalanceValidate(t *testing.T) {
+	key := getStringFromItemType(KEY)
+	tests := []struct {
+		name   string
+		change func(b *Balance)
+		want   string // part of the error
+	}{
+		{"unknown room type", func(b *Balance) { b.RoomChances["Attic"] = 0 }, `unknown room type "Attic" in RoomChances`},
+		{"room chances off", func(b *Balance) { b.RoomChances[getPrintStringFromRoomType(getGenetateableTypes()[0])] += 0.5 }, "RoomChances must sum to 1"},
+		{"chance above 1", func(b *Balance) { b.WallChance = 1.5 }, "WallChance must be between 0 and 1"},
+		{"too sticky", func(b *Balance) { b.HowSticky = 0.3 }, "HowSticky"},
+		{"chest gold backwards", func(b *Balance) { b.ChestGold.Min, b.ChestGold.Max = 5, 1 }, "ChestGold must have"},
+		{"not enough keys", func(b *Balance) { b.ChestLockedChance = b.ItemChances[key] + 0.1 }, "must be at least ChestLockedChance"},
+		{"tiers off", func(b *Balance) { b.ItemTiers[key] = []ItemTier{{Chance: 0.5, Effect: 1}} }, "ItemTiers " + key + " must sum to 1"},
+		{"unknown tier status", func(b *Balance) {
+			b.ItemTiers[key] = []ItemTier{{Chance: 1, Effect: 1, Status: "Sleepy", StatusTurns: 1}}
+		}, `unknown status "Sleepy"`},
+		{"no inventory", func(b *Balance) { b.InventorySize = 0 }, "InventorySize must be at least 1"},
+		{"stacked equipment", func(b *Balance) { b.StackSizes[getStringFromItemType(getEquipTypes()[0])] = 2 }, "cannot stack"},
+		{"no carry weight", func(b *Balance) { b.CarryWeight = 0 }, "CarryWeight must be above 0"},
+		{"missing chest counts", func(b *Balance) {
+			delete(b.ChestCounts, getPrintStringFromRoomType(getGenetateableTypes()[0]))
+		}, "missing from ChestCounts"},
+		{"spawned warlord", func(b *Balance) {
+			name := getPrintStringFromRoomType(getGenetateableTypes()[0])
+			b.EnemySpawns[name] = []EnemySpawn{{Chance: 1, Enemies: []string{getEnemyNameFromType(WARLORD)}}}
+		}, "only guards the"},
+		{"missing enemy xp", func(b *Balance) { delete(b.EnemyXP, getEnemyNameFromType(PEON)) }, "missing from EnemyXP"},
+		{"no starting moves", func(b *Balance) { b.StartingMoves = nil }, "at least one starting move"},
+		{"loadout too small", func(b *Balance) { b.LoadoutSize = len(b.StartingMoves) - 1 }, "LoadoutSize"},
+		{"two moves with a name", func(b *Balance) { b.LearnableMoves = append(b.LearnableMoves, b.StartingMoves[0]) }, "there are two moves named"},
+		{"backwards move", func(b *Balance) { b.StartingMoves[0].MinDamage = b.StartingMoves[0].MaxDamage + 1 }, "has invalid stats"},
+		{"sell share above 1", func(b *Balance) { b.SellShare = 2 }, "SellShare must be between 0 and 1"},
+		{"rarity prices short", func(b *Balance) { b.RarityPriceScales = b.RarityPriceScales[1:] }, "RarityPriceScales must have"},
+		{"unknown difficulty", func(b *Balance) { b.Difficulty = "Nightmare" }, `Difficulty "Nightmare" is not one of the Difficulties`},
+		{"shrine statuses off", func(b *Balance) { b.ShrineStatuses = b.ShrineStatuses[1:] }, "ShrineStatuses must sum to 1"},
+	}
+	for _, test := range tests {
+		b := defaultBalance()
+		test.change(b)
+		err := b.validate()
+		if err == nil {
+			t.Errorf("%s: the balance is valid", test.name)
+		} else if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %q, want it to mention %q", test.name, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import "math"
+
+// Enemy behaviours. Every EnemyType can plug in its own enemyBehaviour, types
+// without one just attack every turn. Behaviours are driven by turnCounter,
+// which has already been counted up for the current turn, so it is 1 on the
+// enemy's first turn.
+
+type enemyBehaviour func(e *Enemy, p *Player, res *Result)
+
+const (
+	PeonFleeTurn        = 3    // first turn a peon may flee on
+	PeonFleeHealth      = 0.35 // share of its health a peon has to be under to flee
+	PeonFleeChance      = 0.3
+	BruteHeavyBlow      = 2.5 // damage multiplier of the blow a brute charged up
+	WarriorBlockDefense = 6.0 // defense a blocking warrior adds until its next turn
+	MysticHeal          = 15.0
+	MysticDrain         = 0.5 // share of the damage drained back as health
+	MysticCurse         = 0.2 // share of the player's strength a drain takes away
+	MysticCurseTurns    = 2
+	WarlordCleave       = 2.0 // damage multiplier of the warlord's cleave
+	WarlordSunder       = 3.0 // defense the cleave takes off of the player
+	WarlordGuard        = 5.0 // defense the warlord raises every fourth turn
+	WarlordStatusTurns  = 2
+)
+
+var enemyBehaviours = map[EnemyType]enemyBehaviour{
+	PEON:     peonBehaviour,
+	WARRIOR:  warriorBehaviour,
+	BRUTE:    bruteBehaviour,
+	E_MYSTIC: mysticBehaviour,
+	WARLORD:  warlordBehaviour,
+}
+
+func getEnemyBehaviour(eType EnemyType) enemyBehaviour {
+	if behaviour, ok := enemyBehaviours[eType]; ok {
+		return behaviour
+	}
+	return attackBehaviour
+}
+
+// attack hits the player for the enemy's damage times scale and returns the
+// damage done.
+func (e *Enemy) attack(p *Player, res *Result, scale float64) float64 {
+	damage := rollDamage(p.game.rng, BaseEnemyMinDamage*scale, BaseEnemyMaxDamage*scale, e.getStrength(), p.getDefense())
+	p.health -= damage
+	res.addEvent(EventEnemyAttack, damage, "The %s attacked and did %.2f damage.", getEnemyNameFromType(e.eType), damage)
+	return damage
+}
+
+// heal gives the enemy up to amount health back and returns how much it got.
+func (e *Enemy) heal(amount float64) float64 {
+	healed := math.Min(amount, e.maxHealth-e.health)
+	e.health += healed
+	return healed
+}
+
+func attackBehaviour(e *Enemy, p *Player, res *Result) {
+	e.attack(p, res, 1)
+}
+
+// peonBehaviour attacks, but a badly hurt peon may run off after a few turns.
+func peonBehaviour(e *Enemy, p *Player, res *Result) {
+	if e.turnCounter >= PeonFleeTurn && e.health < e.maxHealth*PeonFleeHealth && p.game.rng.Float64() < PeonFleeChance {
+		p.currentRoom.removeEnemy(e)
+		res.addEvent(EventEnemyFled, 0, "The %s fled the room!", getEnemyNameFromType(e.eType))
+		return
+	}
+	e.attack(p, res, 1)
+}
+
+// warriorBehaviour raises its shield every third turn instead of attacking.
+func warriorBehaviour(e *Enemy, p *Player, res *Result) {
+	if e.isBlocking() {
+		res.addEvent(EventEnemyBlocking, WarriorBlockDefense, "The %s raised its shield.", getEnemyNameFromType(e.eType))
+		return
+	}
+	e.attack(p, res, 1)
+}
+
+// bruteBehaviour spends every second turn of three winding up and lands a
+// heavy blow on the third.
+func bruteBehaviour(e *Enemy, p *Player, res *Result) {
+	switch {
+	case e.isCharging():
+		res.addEvent(EventEnemyCharging, 0, "The %s is winding up a heavy blow!", getEnemyNameFromType(e.eType))
+	case e.turnCounter%3 == 0:
+		e.attack(p, res, BruteHeavyBlow)
+	default:
+		e.attack(p, res, 1)
+	}
+}
+
+// mysticBehaviour attacks on odd turns. On even turns it heals the most hurt
+// of its allies, or drains and weakens the player if none of them are hurt.
+func mysticBehaviour(e *Enemy, p *Player, res *Result) {
+	if e.turnCounter%2 == 1 {
+		e.attack(p, res, 1)
+		return
+	}
+
+	var hurt *Enemy
+	for _, ally := range p.currentRoom.enemies {
+		if ally == e || !ally.isAlive() || ally.health >= ally.maxHealth {
+			continue
+		}
+		if hurt == nil || ally.health/ally.maxHealth < hurt.health/hurt.maxHealth {
+			hurt = ally
+		}
+	}
+	if hurt != nil {
+		healed := hurt.heal(MysticHeal)
+		res.addEvent(EventEnemyHealed, healed, "The %s healed the %s for %.2f.", getEnemyNameFromType(e.eType), getEnemyNameFromType(hurt.eType), healed)
+		return
+	}
+
+	damage := e.attack(p, res, 1)
+	if healed := e.heal(damage * MysticDrain); healed > 0 {
+		res.addEvent(EventEnemyHealed, healed, "The %s drained %.2f health from you.", getEnemyNameFromType(e.eType), healed)
+	}
+	p.statuses.add(STATUS_WEAKENED, MysticCurse, MysticCurseTurns, "You", res)
+}
+
+// warlordBehaviour raises its guard every fourth turn and cleaves through the
+// player's armor every third, attacking either way.
+func warlordBehaviour(e *Enemy, p *Player, res *Result) {
+	switch {
+	case e.turnCounter%4 == 0:
+		e.statuses.add(STATUS_SHIELDED, WarlordGuard, WarlordStatusTurns, e.getWho(), res)
+		e.attack(p, res, 1)
+	case e.turnCounter%3 == 0:
+		e.attack(p, res, WarlordCleave)
+		p.statuses.add(STATUS_ARMOR_BROKEN, WarlordSunder, WarlordStatusTurns, "You", res)
+	default:
+		e.attack(p, res, 1)
+	}
+}
+
+// isBlocking is true from a warrior's block turn until its next turn.
+func (e *Enemy) isBlocking() bool {
+	return e.eType == WARRIOR && e.turnCounter > 0 && e.turnCounter%3 == 0
+}
+
+// isCharging is true from a brute's wind up turn until it lands the blow.
+func (e *Enemy) isCharging() bool {
+	return e.eType == BRUTE && e.turnCounter%3 == 2
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// The damage model. Every hit in combat, the player's moves and the enemies'
+// attacks alike, goes through calcDamage so both sides follow the same rules.
+
+// calcDamage scales the raw damage of an attack by the attacker's strength and
+// takes the defender's defense off of it. Damage never goes below zero, a
+// strong enough defense blocks a hit completely instead of healing.
+func calcDamage(raw, strength, defense float64) float64 {
+	return math.Max(0, raw*strength-defense)
+}
+
+// rollDamage rolls the raw damage of an attack between min and max and runs it
+// through calcDamage.
+func rollDamage(rng *rand.Rand, min, max, strength, defense float64) float64 {
+	raw := min + rng.Float64()*(max-min)
+	return calcDamage(raw, strength, defense)
+}
+
+// getDefense is the player's base defense plus the defense boost of their
+// equipment and their statuses.
+func (p *Player) getDefense() float64 {
+	return p.defense + p.inventory.getEquipStats().Defense + p.statuses.defenseBonus()
+}
+
+func (p *Player) getStrength() float64 {
+	return (p.strength + p.inventory.getEquipStats().Strength) * p.statuses.strengthScale()
+}
+
+// getWeaponDamage is the raw damage the equipped weapon adds to every move
+// that does damage.
+func (p *Player) getWeaponDamage() float64 {
+	return p.inventory.getEquipStats().Damage
+}
+
+// getDefense is the enemy's base defense plus its block and its statuses. It
+// can go below zero when its armor is broken, which makes hits do extra damage.
+func (e *Enemy) getDefense() float64 {
+	defense := float64(BaseEnemyDefense) + e.statuses.defenseBonus()
+	if e.isBlocking() {
+		defense += WarriorBlockDefense
+	}
+	return defense
+}
+
+func (e *Enemy) getStrength() float64 {
+	return e.strength * e.statuses.strengthScale()
+}
@@ -0,0 +1,900 @@
+package main
+
+import "fmt"
+
+// The console client. Everything here reads choices from stdin, turns them
+// into engine Actions and prints the Result.
+
+func (p *Player) update() bool {
+	if p.state == Dead || p.state == Won {
+		p.printSummary()
+		return false
+	}
+	if p.currentRoom.getNumEnemiesAlive() > 0 {
+		p.state = Fighting
+	}
+
+	var run bool
+	if p.state == Exploring {
+		run = p.printChoices()
+	} else if p.state == Fighting {
+		p.printFightingChoices()
+		run = true
+	} else {
+		// IMPOSSIBLE CASE - Try and reset and recover
+		p.state = Exploring
+		run = true
+	}
+
+	if p.state == Dead || p.state == Won {
+		p.printSummary()
+		return false
+	}
+	return run
+}
+
+// printMap draws the explored rooms around the player.
+func (p *Player) printMap() {
+	fmt.Println("\nMap:")
+	for _, line := range p.game.drawMap(*p.loc, MinimapRadius) {
+		fmt.Println(line)
+	}
+	fmt.Printf("%c You  %c Enemies  %c Loot  %c Locked door  lower case: seen, not visited\n", mapPlayer, mapEnemies, mapChests, mapLockedDoor)
+}
+
+// printSummary is the victory or game over screen at the end of a run.
+func (p *Player) printSummary() {
+	if p.state == Won {
+		fmt.Println("\n=====================VICTORY=====================")
+		fmt.Printf("You defeated the %s and conquered the dungeon!\n", getEnemyNameFromType(WARLORD))
+	} else {
+		fmt.Println("\n====================GAME OVER====================")
+	}
+	game := p.game
+	fmt.Printf("Rooms visited  : %d/%d\n", game.countVisitedRooms(), game.width()*game.height())
+	fmt.Printf("Enemies killed : %d\n", p.kills)
+	fmt.Printf("Items used     : %d\n", p.itemsUsed)
+	fmt.Printf("Level reached  : %d\n", p.level)
+	fmt.Printf("Gold           : %d\n", p.gold)
+	fmt.Println("======================END======================")
+}
+
+// act performs the action and prints everything that happened.
+func (p *Player) act(action Action) *Result {
+	res := p.perform(action)
+	printResult(res)
+	return res
+}
+
+func printResult(res *Result) {
+	for _, event := range res.events {
+		fmt.Println(event.message)
+	}
+	if res.err != nil {
+		fmt.Println("Could not do that:", res.err)
+	}
+}
+
+func (p *Player) printChoices() bool {
+	var choice int8
+
+	for {
+		fmt.Println("\nWhat would you like to do?")
+		fmt.Println("1. Explore current room")
+		fmt.Println("2. Move to another room")
+		fmt.Println("3. View Inventory Options")
+		fmt.Println("4. View Player Stats")
+		fmt.Println("5. Manage Moves")
+		fmt.Println("6. Map")
+		fmt.Println("7. Save Game")
+		fmt.Println("8. Load Game")
+		fmt.Println("9. Exit")
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		switch choice {
+		case cheatInputNumber:
+			p.doCheatLoop()
+			continue
+		case 1:
+			p.printRoomOptions()
+			return true
+		case 2:
+			p.printMoveChoices()
+			return true
+		case 3:
+			turnConsumed := p.printInventoryChoices()
+			if turnConsumed {
+				return true
+			}
+			continue
+		case 4:
+			p.printPlayerStats()
+		case 5:
+			p.printLoadoutChoices()
+		case 6:
+			p.printMap()
+		case 7:
+			p.game.printSaveChoices(false)
+		case 8:
+			p.game.printSaveChoices(true)
+			// the loaded game has its own player, hand control back to the game loop
+			return true
+		case 9:
+			return false
+		default:
+			fmt.Println("Invalid Input, try again")
+		}
+	}
+}
+
+func (p *Player) printRoomOptions() {
+	if DEBUG_MODE {
+		fmt.Println("\nDebug prints.")
+		fmt.Printf("Player Location: %+v", *p.loc)
+		fmt.Printf("Room Location: %+v", p.currentRoom.loc)
+		fmt.Printf("Room Type=%s\n", getPrintStringFromRoomType(p.currentRoom.rType))
+		fmt.Println("Room ID", p.currentRoom.id)
+	}
+
+	fmt.Printf("\nYou are in a %s, located at %+v\n", getPrintStringFromRoomType(p.currentRoom.rType), *p.loc)
+	if interaction, ok := p.currentRoom.getInteraction(); ok {
+		p.printInteractionChoice(interaction)
+	}
+	if p.currentRoom.rType == MERCHANT {
+		p.printShopChoices()
+	}
+	totalChests := p.currentRoom.getNumChests()
+	numUnlockedChest := p.currentRoom.getNumLootableChests()
+	numLockedChests := p.currentRoom.getNumLockedChests()
+
+	if numLockedChests+numUnlockedChest == 0 && totalChests != 0 {
+		fmt.Println("All chests in this room have been looted.")
+		return
+	}
+	if totalChests == 0 {
+		fmt.Println("There are no chests in this room.")
+		return
+	} else if totalChests == 1 {
+		if numLockedChests == totalChests {
+			fmt.Println("There is 1 locked chest and no unlocked chests in the room")
+			fmt.Println("To unlock the chest, use a key from the inventory menu")
+			return
+		} else if numUnlockedChest == totalChests {
+			fmt.Println("There are no locked chests and 1 unlocked chest in the room")
+			fmt.Println("Would you like to loot it?")
+		}
+	} else { // totalChests > 1
+		if numLockedChests == totalChests {
+			fmt.Println("There are", numLockedChests, "locked chests and no unlocked chests in the room")
+			fmt.Println("To unlock the chests, use a key or keys from the inventory menu")
+			return
+		} else if numLockedChests == 0 {
+			fmt.Println("There are no locked chests and", numUnlockedChest, "unlocked chests in the room")
+			fmt.Println("Would you like to loot them all?")
+		} else { // one or more for both
+			if numLockedChests == 1 {
+				fmt.Println("There is 1 locked chest and", numUnlockedChest, "unlocked chests in the room")
+				fmt.Println("To unlock the locked chest, use a key from the inventory menu")
+				fmt.Println("Would you like to loot all the unlocked chests?")
+			} else if numUnlockedChest == 1 {
+				fmt.Println("There are", numLockedChests, "locked chests and 1 unlocked chest in the room")
+				fmt.Println("To unlock the locked chests, use a key from the inventory menu")
+				fmt.Println("Would you like to loot the unlocked chest?")
+			} else {
+				fmt.Println("There are", numLockedChests, "locked chests and", numUnlockedChest, "unlocked chests in the room")
+				fmt.Println("To unlock the locked chests, use a key from the inventory menu")
+				fmt.Println("Would you like to loot all the unlocked chests?")
+			}
+		}
+	}
+	var choice int8
+	fmt.Println("  1: Yes")
+	fmt.Println("Any: No")
+	_, err := fmt.Scanln(&choice)
+	if err != nil {
+		fmt.Println("An error occured while reading your choice in, please try again: ", err)
+	}
+	if choice == 1 {
+		p.act(lootAction())
+	} else {
+		fmt.Println("You can come back to loot the chests at any time")
+	}
+}
+
+func (p *Player) printFightingChoices() {
+	enemy := p.currentRoom.getCurrentEnemy()
+	if enemy == nil {
+		p.state = Exploring
+		return
+	}
+
+	var choice int8
+	for {
+		fmt.Println("\nIt's turn", enemy.turnCounter+1)
+		if len(p.statuses) > 0 {
+			fmt.Printf("Your Health : %6.2f    [%s]\n", p.health, p.statuses.describe())
+		} else {
+			fmt.Printf("Your Health : %6.2f\n", p.health)
+		}
+		for i, slot := range p.currentRoom.getLivingEnemySlots() {
+			current := p.currentRoom.enemies[slot]
+			status := ""
+			if current.isBlocking() {
+				status += " (Blocking)"
+			} else if current.isCharging() {
+				status += " (Charging)"
+			}
+			if len(current.statuses) > 0 {
+				status += " [" + current.statuses.describe() + "]"
+			}
+			fmt.Printf("Enemy %d Health: %6.2f    Enemy type: %s%s\n", i+1, current.health, getEnemyNameFromType(current.eType), status)
+		}
+
+		var index int
+		var move *Move
+		fmt.Println("Moves:")
+		for index, move = range p.moves {
+			if move.cooldown > 0 {
+				fmt.Printf("  %2d: %-15s On %d turn cooldown\n", index, move.name, move.cooldown)
+			} else {
+				fmt.Printf("  %2d: %s\n", index, move.describe(p.getStrength(), p.getWeaponDamage()))
+			}
+		}
+		fmt.Println("Other options:")
+		index++
+		fmt.Printf("  %2d: Inventory\n", index)
+		index++
+		fmt.Printf("  %2d: Run Away\n", index)
+		runIndex := index
+		quickSlots := p.inventory.getQuickSlots()
+		if len(quickSlots) > 0 {
+			fmt.Println("Quick items:")
+			for _, slot := range quickSlots {
+				index++
+				item := p.inventory.itemSlots[slot]
+				fmt.Printf("  %2d: %-7s %6.2f%s\n", index, getStringFromItemType(item.iType), item.effect, item.describeCount())
+			}
+		}
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			fmt.Println("Your turn was not consumed.")
+			continue
+		}
+
+		if choice == cheatInputNumber {
+			p.doCheatLoop()
+		} else if choice >= 0 && int(choice) < len(p.moves) {
+			target, ok := p.printTargetChoices(p.moves[choice].needsTarget())
+			if !ok {
+				continue
+			}
+			res := p.act(attackAction(int(choice), target))
+			if res.err == errOnCooldown {
+				move = p.moves[choice]
+				fmt.Printf("Move %-15s is on %d turn cooldown\n", move.name, move.cooldown)
+			}
+			if res.turnConsumed {
+				return
+			}
+		} else if int(choice) == runIndex-1 {
+			if p.printInventoryChoices() {
+				return
+			}
+		} else if int(choice) == runIndex {
+			if p.printRunChoices() {
+				return
+			}
+		} else if int(choice) > runIndex && int(choice) <= index {
+			slot := quickSlots[int(choice)-runIndex-1]
+			target, ok := 0, true
+			if p.getDamageItemAt(slot) != nil {
+				target, ok = p.printTargetChoices(true)
+			}
+			if !ok {
+				continue
+			}
+			if p.act(useItemAction(slot, target)).turnConsumed {
+				return
+			}
+		} else {
+			fmt.Println("Invalid Input, try again")
+			fmt.Println("Your turn was not consumed.")
+		}
+	}
+}
+
+// printTargetChoices asks which enemy a move or damage item should hit and
+// returns its index in the room. There is nothing to ask if it does not need a
+// target, see Move.needsTarget, or there is only one enemy left. ok is false if
+// the player picked the cancel option.
+func (p *Player) printTargetChoices(needsTarget bool) (target int, ok bool) {
+	slots := p.currentRoom.getLivingEnemySlots()
+	if !needsTarget || len(slots) == 1 {
+		return slots[0], true
+	}
+
+	var choice int8
+	for {
+		fmt.Println("Which enemy would you like to attack?")
+		for i, slot := range slots {
+			enemy := p.currentRoom.enemies[slot]
+			fmt.Printf("  %2d: %-7s Health %6.2f\n", i+1, getEnemyNameFromType(enemy.eType), enemy.health)
+		}
+		fmt.Printf("  %2d: Cancel\n", len(slots)+1)
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		if int(choice) == len(slots)+1 {
+			return 0, false
+		}
+		if choice >= 1 && int(choice) <= len(slots) {
+			return slots[choice-1], true
+		}
+		fmt.Println("Invalid Input, try again")
+	}
+}
+
+// getKeyAt returns the key at slot, nil if there is none.
+func (p *Player) getKeyAt(slot int) *Item {
+	if slot < 0 || slot >= p.inventory.size() {
+		return nil
+	}
+	if item := p.inventory.itemSlots[slot]; item != nil && item.iType == KEY {
+		return item
+	}
+	return nil
+}
+
+// getDamageItemAt returns the damage item at slot, nil if there is none.
+func (p *Player) getDamageItemAt(slot int) *Item {
+	if slot < 0 || slot >= p.inventory.size() {
+		return nil
+	}
+	if item := p.inventory.itemSlots[slot]; item != nil && item.iType == INSTANT_DAMAGE {
+		return item
+	}
+	return nil
+}
+
+// printChestChoices shows what the locked chests in the room hold and asks
+// which of them the key should unlock, one at a time until its charges run out
+// or the player is done. ok is false if the player picked none.
+func (p *Player) printChestChoices(key *Item) (chests []int, ok bool) {
+	locked := p.currentRoom.getLockedChestSlots()
+	picked := make(map[int]bool)
+	var choice int8
+	for len(chests) < len(locked) && float64(len(chests)+1) <= key.effect {
+		fmt.Printf("Which chest would you like to unlock? This key can unlock %3.1f more.\n", key.effect-float64(len(chests)))
+		for i, slot := range locked {
+			if picked[slot] {
+				continue
+			}
+			fmt.Printf("  %2d: %s\n", i+1, p.currentRoom.chests[slot].describe(p.game.balance))
+		}
+		fmt.Printf("  %2d: Done\n", len(locked)+1)
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		if int(choice) == len(locked)+1 {
+			break
+		}
+		if choice < 1 || int(choice) > len(locked) || picked[locked[choice-1]] {
+			fmt.Println("Invalid Input, try again")
+			continue
+		}
+		picked[locked[choice-1]] = true
+		chests = append(chests, locked[choice-1])
+	}
+	if len(chests) == 0 {
+		fmt.Println("No chests were picked, canceling")
+		return nil, false
+	}
+	return chests, true
+}
+
+// printDirectionChoices lists the doors out of the current room, locked ones
+// included, and reads a direction. ok is false if the player picked the cancel option.
+func (p *Player) printDirectionChoices(prompt string) (dir Direction, ok bool) {
+	var choice int8
+	for {
+		fmt.Println(prompt)
+		for side := UP; side <= RIGHT; side++ {
+			door := p.currentRoom.getDoor(side)
+			if p.game.getNeighbour(p.loc.x, p.loc.y, side) == nil {
+				continue
+			}
+			if door.exists && door.locked {
+				fmt.Printf("%d. %s (locked)\n", side+1, getStringFromDirection(side))
+			} else if door.exists {
+				fmt.Printf("%d. %s\n", side+1, getStringFromDirection(side))
+			}
+		}
+		fmt.Println("5. Cancel")
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		if choice == 5 {
+			return 0, false
+		}
+
+		choice-- // due to directions being index 0 based and prints being index 1 based
+		dir = Direction(choice)
+		if dir >= UP && dir <= RIGHT && p.currentRoom.getDoor(dir).exists && p.game.getNeighbour(p.loc.x, p.loc.y, dir) != nil {
+			if DEBUG_MODE {
+				fmt.Println(getStringFromDirection(dir))
+			}
+			return dir, true
+		}
+		fmt.Println("Invalid Input, try again")
+	}
+}
+
+func (p *Player) printRunChoices() (turnConsumed bool) {
+	dir, ok := p.printDirectionChoices("Where would you like to run to?")
+	if !ok {
+		return false
+	}
+	return p.act(runAction(dir)).turnConsumed
+}
+
+func (p *Player) printPlayerStats() {
+	fmt.Println("\nPlayer Stats:")
+	fmt.Printf("Level    = %d (%.0f/%.0f xp to the next level)\n", p.level, p.xp, p.getXPForNextLevel())
+	fmt.Printf("Health   = %.2f/%.2f\n", p.health, p.getMaxHealth())
+	fmt.Println("Defense  =", p.getDefense())
+	fmt.Println("Strength =", p.getStrength())
+	fmt.Println("Gold     =", p.gold)
+	if len(p.statuses) > 0 {
+		fmt.Println("Statuses =", p.statuses.describe())
+	}
+}
+
+func (p *Player) printMoveChoices() {
+	for {
+		dir, ok := p.printDirectionChoices("\nWhere would you like to go?")
+		if !ok {
+			return
+		}
+		if p.currentRoom.getDoor(dir).locked && !p.printUnlockChoice(dir) {
+			continue
+		}
+		if p.act(moveAction(dir)).err == nil {
+			return
+		}
+	}
+}
+
+// printUnlockChoice asks whether to spend a key on the locked door in dir and
+// returns true if it was unlocked.
+func (p *Player) printUnlockChoice(dir Direction) bool {
+	var choice int8
+	fmt.Println("The door is locked. Use a key to unlock it?")
+	fmt.Println("1. Yes")
+	fmt.Println("2. No")
+	_, err := fmt.Scanln(&choice)
+	if err != nil || choice != 1 {
+		return false
+	}
+	return p.act(unlockAction(dir)).err == nil
+}
+
+// printInteractionChoice offers the special interaction of the room.
+func (p *Player) printInteractionChoice(interaction roomInteraction) {
+	var choice int8
+	fmt.Println(interaction.prompt)
+	fmt.Println("  1: Yes")
+	fmt.Println("Any: No")
+	_, err := fmt.Scanln(&choice)
+	if err != nil || choice != 1 {
+		return
+	}
+	p.act(interactAction())
+}
+
+// printLoadoutChoices shows the loadout and lets the player swap the other
+// moves they know into it.
+func (p *Player) printLoadoutChoices() {
+	var choice int8
+	for {
+		fmt.Printf("\nLoadout (%d/%d):\n", len(p.moves), p.game.balance.LoadoutSize)
+		for i, move := range p.moves {
+			fmt.Printf("  %2d: %s\n", i+1, move.describe(p.getStrength(), p.getWeaponDamage()))
+		}
+		spare := p.getSpareMoves()
+		if len(spare) == 0 {
+			fmt.Println("You do not know any other moves.")
+			return
+		}
+		fmt.Println("Other known moves:")
+		for i, move := range spare {
+			fmt.Printf("  %2d: %s\n", i+1, move.describe(p.getStrength(), p.getWeaponDamage()))
+		}
+
+		slots := len(p.moves)
+		if slots < p.game.balance.LoadoutSize {
+			slots++
+			fmt.Printf("Which loadout slot would you like to change? (%d adds a move, 0 to leave)\n", slots)
+		} else {
+			fmt.Println("Which loadout slot would you like to change? (0 to leave)")
+		}
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		if choice == 0 {
+			return
+		}
+		if choice < 0 || int(choice) > slots {
+			fmt.Println("Invalid Input, try again")
+			continue
+		}
+		slot := int(choice) - 1
+
+		fmt.Println("Which of your other moves should go there?")
+		_, err = fmt.Scanln(&choice)
+		if err != nil || choice < 1 || int(choice) > len(spare) {
+			fmt.Println("Invalid Input, try again")
+			continue
+		}
+		for known, move := range p.knownMoves {
+			if move == spare[choice-1] {
+				p.act(swapMoveAction(slot, known))
+			}
+		}
+	}
+}
+
+// readSlot prints the item inventory and reads a slot index from the player.
+func (p *Player) readSlot(prompt string) (int, bool) {
+	var choice int8
+	fmt.Println(prompt)
+	p.inventory.printItemInventory()
+	_, err := fmt.Scanln(&choice)
+	if err != nil {
+		fmt.Println("An error occured while reading your choice in, please try again: ", err)
+		return 0, false
+	}
+	return int(choice), true
+}
+
+func (p *Player) printSlotError(res *Result) {
+	if res.err == errInvalidSlot {
+		fmt.Printf("Please pick from the range 0-%-2d\n", p.inventory.size()-1)
+	}
+}
+
+func (p *Player) printInventoryChoices() (turnConsumed bool) {
+	var choice int8
+	done := false
+	for !done {
+		fmt.Println("\nWhat inventory action would you like to do?")
+		fmt.Println("1. View Inventory")
+		fmt.Println("2. Use Item")
+		fmt.Println("3. Equip Item")
+		if p.currentRoom.rType == MERCHANT {
+			fmt.Println("4. Sell Item")
+		} else {
+			fmt.Println("4. Discard Item")
+		}
+		fmt.Println("5. Equipment")
+		fmt.Println("6. Leave Inventory")
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+
+		switch choice {
+		case cheatInputNumber:
+			p.doCheatLoop()
+		case 1:
+			p.inventory.printFullInventory()
+		case 2:
+			if p.inventory.slotsUsed() == 0 {
+				fmt.Println("There are no items in your inventory")
+				break
+			}
+
+			if p.inventory.numUseables() <= 0 {
+				fmt.Println("There are no useable items in your inventory")
+				break
+			}
+
+			for {
+				slot, ok := p.readSlot("\nWhich item would you like to use? (Select by number):")
+				if !ok {
+					continue
+				}
+				action := useItemAction(slot, 0)
+				if p.getDamageItemAt(slot) != nil && p.state == Fighting {
+					target, ok := p.printTargetChoices(true)
+					if !ok {
+						break
+					}
+					action = useItemAction(slot, target)
+				} else if key := p.getKeyAt(slot); key != nil && p.currentRoom.getNumLockedChests() > 0 {
+					chests, ok := p.printChestChoices(key)
+					if !ok {
+						break
+					}
+					action = unlockChestsAction(slot, chests)
+				}
+				res := p.act(action)
+				p.printSlotError(res)
+				if res.turnConsumed {
+					turnConsumed = true
+					done = true
+					break
+				}
+				if res.err == errNoLockedChests || res.err == errFullHealth || res.err == errNotFighting || res.err == errInventoryMaxed || res.err == errInventoryFull {
+					// valid input, but kick them back to the inventory choices list
+					break
+				}
+			}
+		case 3:
+			if p.inventory.slotsUsed() == 0 {
+				fmt.Println("There are no items in your inventory")
+				break
+			}
+
+			if p.inventory.numEquipables() <= 0 {
+				fmt.Println("There are no equipable items in your inventory")
+				break
+			}
+
+			for {
+				slot, ok := p.readSlot("\nWhich item would you like to equip? (Select by number):")
+				if !ok {
+					continue
+				}
+				if item, ok := p.inventory.isEquipable(slot); ok && p.inventory.getEquipped(item.iType) != nil {
+					name := getStringFromItemType(item.iType)
+					fmt.Printf("There is already an equiped %s item.\n", name)
+					fmt.Printf("Equipping a new %s item will swap it into the new item's slot.\n", name)
+					fmt.Println("Would you like to continue?")
+					fmt.Println("  1: Yes")
+					fmt.Println("Any: No")
+					_, err := fmt.Scanln(&choice)
+					if err != nil {
+						fmt.Println("An error occured while reading your choice in, please try again: ", err)
+						break
+					}
+
+					if choice != 1 {
+						fmt.Println("Canceling equip process")
+						break
+					}
+				}
+				res := p.act(equipAction(slot))
+				p.printSlotError(res)
+				if res.turnConsumed {
+					turnConsumed = true
+					done = true
+					break
+				}
+			}
+		case 4:
+			if p.printDiscardChoices() {
+				turnConsumed = true
+				done = true
+			}
+		case 5:
+			if p.printEquipmentChoices() {
+				turnConsumed = true
+				done = true
+			}
+		case 6:
+			done = true
+		default:
+			fmt.Println("Invalid choice")
+		}
+	}
+	return
+}
+
+// printDiscardChoices asks which item to discard, or to sell in a Merchant
+// room, and confirms it first.
+func (p *Player) printDiscardChoices() (turnConsumed bool) {
+	if p.inventory.slotsUsed() == 0 {
+		fmt.Println("There are no items in your inventory")
+		return false
+	}
+	verb, done := "discard", "discarded"
+	selling := p.currentRoom.rType == MERCHANT
+	if selling {
+		verb, done = "sell", "sold"
+	}
+
+	var choice int8
+	for {
+		slot, ok := p.readSlot(fmt.Sprintf("\nWhich item would you like to %s? (Select by number):\nEnter -1 to cancel", verb))
+		if !ok {
+			continue
+		}
+		if slot == -1 {
+			fmt.Printf("Canceling, no item was %s\n", done)
+			return false
+		}
+		if slot < 0 || slot >= p.inventory.size() {
+			fmt.Println("Selected index does not exist.")
+			fmt.Printf("Please pick from the range 0-%-2d\n", p.inventory.size()-1)
+			continue
+		}
+		item := p.inventory.itemSlots[slot]
+		if item == nil {
+			fmt.Println("There is no item in that slot")
+			continue
+		}
+		if selling {
+			fmt.Printf("You are about to sell the following item for %d gold:\n", item.getSellPrice(p.game.balance))
+		} else {
+			fmt.Println("You are about to discard the following item:")
+		}
+		p.inventory.printItemAt(slot)
+		fmt.Println("\nDo you wish to continue?")
+		fmt.Printf("  1: Yes, %s the item\n", verb)
+		fmt.Println("Any: No, keep the item")
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+
+		if choice != 1 {
+			fmt.Printf("Item will not be %s\n", done)
+			return false
+		}
+		return p.act(discardAction(slot)).turnConsumed
+	}
+}
+
+// printShopChoices lets the player trade with the merchant of the room until
+// they leave the shop.
+func (p *Player) printShopChoices() {
+	var choice int8
+	for {
+		fmt.Printf("\nA merchant has set up shop here. You have %d gold.\n", p.gold)
+		fmt.Println("1. Buy")
+		fmt.Println("2. Buy Back")
+		fmt.Println("3. Sell Item")
+		fmt.Println("4. Leave Shop")
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		switch choice {
+		case 1:
+			p.printWareChoices(false)
+		case 2:
+			p.printWareChoices(true)
+		case 3:
+			p.printDiscardChoices()
+		case 4:
+			return
+		default:
+			fmt.Println("Invalid choice")
+		}
+	}
+}
+
+// printWareChoices lists the merchant's stock, or what can be bought back,
+// with the price of each and buys the one the player picks.
+func (p *Player) printWareChoices(buyBack bool) {
+	var choice int8
+	for {
+		wares := p.currentRoom.getWares(buyBack)
+		if len(wares) == 0 {
+			if buyBack {
+				fmt.Println("You have not sold anything here that can be bought back")
+			} else {
+				fmt.Println("The merchant has nothing left to sell")
+			}
+			return
+		}
+		fmt.Printf("\nWhat would you like to buy? You have %d gold.\n", p.gold)
+		for i, item := range wares {
+			fmt.Printf("  %2d: %5d gold  %s\n", i+1, getWarePrice(item, buyBack, p.game.balance), item.describe(p.game.balance))
+		}
+		fmt.Printf("  %2d: Back\n", len(wares)+1)
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		if int(choice) == len(wares)+1 {
+			return
+		}
+		if choice < 1 || int(choice) > len(wares) {
+			fmt.Println("Invalid choice")
+			continue
+		}
+		if buyBack {
+			p.act(buyBackAction(int(choice) - 1))
+		} else {
+			p.act(buyAction(int(choice) - 1))
+		}
+	}
+}
+
+// printEquipmentChoices shows the equipment with the stats it adds up to and
+// offers to take an item off.
+func (p *Player) printEquipmentChoices() (turnConsumed bool) {
+	var choice int8
+	for {
+		fmt.Println("\nEquipment:")
+		p.inventory.printEquipment()
+		fmt.Printf("Damage = +%.2f, Defense = %.2f, Strength = %.2f, Health = %.2f/%.2f\n", p.getWeaponDamage(), p.getDefense(), p.getStrength(), p.health, p.getMaxHealth())
+
+		types := getEquipTypes()
+		fmt.Println("\nWhich item would you like to unequip?")
+		for i, iType := range types {
+			fmt.Printf("  %d: %s\n", i+1, getStringFromItemType(iType))
+		}
+		fmt.Printf("  %d: Back\n", len(types)+1)
+
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		if int(choice) == len(types)+1 {
+			return false
+		}
+		if choice < 1 || int(choice) > len(types) {
+			fmt.Println("Invalid choice")
+			continue
+		}
+		res := p.act(unequipAction(types[choice-1]))
+		if res.turnConsumed {
+			return true
+		}
+	}
+}
+
+func (p *Player) doCheatLoop() {
+	var choice int8
+	valid := false
+	for !valid {
+		_, err := fmt.Scanln(&choice)
+		if err != nil {
+			fmt.Println("An error occured while reading your choice in, please try again: ", err)
+			continue
+		}
+		switch choice {
+		case -1: // leave cheat loop
+			valid = true
+		case 1: // give item
+			effect := 0.0
+			fmt.Scanln(&choice, &effect)
+			item := NewItem(ItemType(choice), effect)
+			if err := p.inventory.addItem(item); err != nil {
+				fmt.Println("failed to give item:", err)
+			} else {
+				fmt.Printf("Given item %+v\n", item)
+			}
+		// todo more cheat options
+		default:
+			// do nothing
+		}
+	}
+}
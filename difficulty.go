@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Difficulty scaling. Rooms further from the start room have tougher and more
+// enemies, and more and better loot, following the curve of the Difficulty
+// preset the balance picks.
+
+// getDifficulty is the preset the world is generated with.
+func (b *Balance) getDifficulty() Difficulty {
+	return b.Difficulties[b.Difficulty]
+}
+
+// getDifficultyNames lists the presets from the gentlest curve to the
+// steepest, for menus.
+func (b *Balance) getDifficultyNames() []string {
+	names := make([]string, 0, len(b.Difficulties))
+	for name := range b.Difficulties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, c := b.Difficulties[names[i]], b.Difficulties[names[j]]
+		if a.PerRing != c.PerRing {
+			return a.PerRing < c.PerRing
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// getDepth is how far into the difficulty curve ring is.
+func (d Difficulty) getDepth(ring int64) float64 {
+	return math.Min(d.MaxDepth, d.PerRing*math.Pow(float64(ring), d.Exponent))
+}
+
+// shiftChances moves chance toward the later entries of chances, by shift at a
+// depth of 1. The result still sums to 1 and entries with no chance keep none.
+func shiftChances(chances []float64, shift, depth float64) []float64 {
+	shifted := append([]float64(nil), chances...)
+	if len(chances) < 2 || shift*depth == 0 {
+		return shifted
+	}
+	sum := 0.0
+	for i := range shifted {
+		shifted[i] *= math.Pow(1+shift*depth, float64(i)/float64(len(shifted)-1))
+		sum += shifted[i]
+	}
+	if sum == 0 {
+		return append(shifted[:0], chances...)
+	}
+	for i := range shifted {
+		shifted[i] /= sum
+	}
+	return shifted
+}
+
+// scale makes the enemy as much tougher as depth calls for.
+func (e *Enemy) scale(d Difficulty, depth float64) {
+	e.maxHealth *= 1 + d.EnemyHealth*depth
+	e.health = e.maxHealth
+	e.strength *= 1 + d.EnemyStrength*depth
+}
+
+// getRing is the ring around the start room that (x, y) is on, the start room
+// being ring 0. Maps can put the start room anywhere, so rings near an edge of
+// the map are cut off.
+func (game *Game) getRing(x, y int64) int64 {
+	dx, dy := x-game.start.x, y-game.start.y
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+// printDifficultyChoices asks which difficulty preset to play on and sets it
+// in balance. Anything but a valid choice keeps the current one.
+func printDifficultyChoices(balance *Balance) {
+	names := balance.getDifficultyNames()
+	fmt.Printf("Choose a difficulty (Enter for %s):\n", balance.Difficulty)
+	for i, name := range names {
+		fmt.Printf("  %d: %s\n", i+1, name)
+	}
+	var choice int
+	_, err := fmt.Scanln(&choice)
+	if err != nil || choice < 1 || choice > len(names) {
+		return
+	}
+	balance.Difficulty = names[choice-1]
+}
@@ -0,0 +1,104 @@
+package main
+
+import "fmt"
+
+// doorEdge is the door on the dir side of the room at loc.
+type doorEdge struct {
+	loc Location
+	dir Direction
+}
+
+// connectRooms makes sure every room can be reached from start. Whenever a
+// walk from start gets stuck, a random wall or locked door on the edge of what
+// it reached is turned into an open door.
+func (game *Game) connectRooms(start Location) {
+	reached := game.walkFromStart(start, true)
+	if DEBUG_MODE {
+		fmt.Println("Rooms reachable from the start:", reached)
+	}
+}
+
+// walkFromStart explores the world from start through open doors only and
+// returns the number of rooms it reached. Keys can be spent on chests or sold,
+// so a locked door is never the only way into a room: every room the walk
+// reaches can be reached without a single key.
+//
+// With repair set, every time the walk runs out of open doors it opens a
+// random wall or locked door between a reached and an unreached room, so it
+// always reaches every room. Without it the world is left untouched.
+func (game *Game) walkFromStart(start Location, repair bool) int64 {
+	reached := make([][]bool, game.height())
+	for y := range reached {
+		reached[y] = make([]bool, game.width())
+	}
+	var count int64
+	var queue []Location
+	var blocked []doorEdge // walls and locked doors out of reached rooms, for repairs
+
+	visit := func(loc Location) {
+		if reached[loc.y][loc.x] {
+			return
+		}
+		reached[loc.y][loc.x] = true
+		count++
+		current := &game.rooms[loc.y][loc.x]
+		for dir := UP; dir <= RIGHT; dir++ {
+			if game.getNeighbour(loc.x, loc.y, dir) == nil {
+				continue
+			}
+			door := current.getDoor(dir)
+			if !door.exists || door.locked {
+				blocked = append(blocked, doorEdge{loc, dir})
+			} else {
+				offset := getOffsetFromDirection(dir)
+				queue = append(queue, Location{loc.x + offset.x, loc.y + offset.y})
+			}
+		}
+	}
+	// leadsOut is true if the edge goes to a room that has not been reached yet
+	leadsOut := func(edge doorEdge) bool {
+		neighbour := game.getNeighbour(edge.loc.x, edge.loc.y, edge.dir)
+		return !reached[neighbour.loc.y][neighbour.loc.x]
+	}
+
+	visit(start)
+	for {
+		if len(queue) > 0 {
+			next := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			visit(next)
+			continue
+		}
+
+		if !repair {
+			break
+		}
+		// stuck, open up a random wall or locked door that leads somewhere new
+		for len(blocked) > 0 {
+			i := game.rng.Intn(len(blocked))
+			edge := blocked[i]
+			blocked[i] = blocked[len(blocked)-1]
+			blocked = blocked[:len(blocked)-1]
+			if leadsOut(edge) {
+				game.openDoor(edge.loc.x, edge.loc.y, edge.dir)
+				queue = append(queue, game.getNeighbour(edge.loc.x, edge.loc.y, edge.dir).loc)
+				break
+			}
+		}
+		if len(queue) == 0 {
+			break
+		}
+	}
+	return count
+}
+
+// openDoor turns the door on the dir side of (x, y) into an open door, on both
+// sides.
+func (game *Game) openDoor(x, y int64, dir Direction) {
+	neighbour := game.getNeighbour(x, y, dir)
+	if neighbour == nil {
+		return
+	}
+	*game.rooms[y][x].getDoor(dir) = Door{true, false}
+	*neighbour.getDoor(getOppositeDirection(dir)) = Door{true, false}
+}
@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+// newWalledGame is a world of radius 1 with a wall between every two rooms.
+func newWalledGame() *Game {
+	game := newBlankGame(testSeed, 1, defaultBalance())
+	for y := range game.rooms {
+		for x := range game.rooms[y] {
+			game.rooms[y][x].rType = HALLWAY
+			game.rooms[y][x].loc = Location{int64(x), int64(y)}
+		}
+	}
+	game.rooms[1][1].rType = START
+	game.start = Location{1, 1}
+	return game
+}
+
+// lockAround puts a locked door on every side of the start room.
+func lockAround(game *Game) {
+	for dir := UP; dir <= RIGHT; dir++ {
+		game.openDoor(1, 1, dir)
+		game.rooms[1][1].getDoor(dir).locked = true
+		game.getNeighbour(1, 1, dir).getDoor(getOppositeDirection(dir)).locked = true
+	}
+}
+
+func TestWalkFromStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(game *Game)
+		repair bool
+		want   int64
+	}{
+		{"walls", func(game *Game) {}, false, 1},
+		{"open door", func(game *Game) { game.openDoor(1, 1, RIGHT) }, false, 2},
+		{"locked doors", lockAround, false, 1},
+		{"a key does not open doors", func(game *Game) {
+			lockAround(game)
+			game.rooms[1][1].chests = []*Chest{{item: NewItem(KEY, 4)}}
+		}, false, 1},
+		{"repaired walls", func(game *Game) {}, true, 9},
+		{"repaired locked doors", lockAround, true, 9},
+	}
+	for _, test := range tests {
+		game := newWalledGame()
+		test.setup(game)
+		if reached := game.walkFromStart(game.start, test.repair); reached != test.want {
+			t.Errorf("%s: reached %d rooms, want %d", test.name, reached, test.want)
+		}
+		if test.repair && game.walkFromStart(game.start, false) != test.want {
+			t.Errorf("%s: the repairs did not last", test.name)
+		}
+	}
+}
+
+// A player who spends every key they find on chests, or sells them, has to
+// be able to get everywhere through open doors.
+func TestEveryRoomIsReachableWithoutKeys(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		game := newGame(seed, testRadius, defaultBalance())
+		for y := range game.rooms {
+			for x := range game.rooms[y] {
+				for _, chest := range game.rooms[y][x].chests {
+					if chest != nil && chest.item != nil && chest.item.iType == KEY {
+						chest.item = nil
+					}
+				}
+			}
+		}
+
+		reached := map[Location]bool{game.start: true}
+		queue := []Location{game.start}
+		for len(queue) > 0 {
+			loc := queue[0]
+			queue = queue[1:]
+			for dir := UP; dir <= RIGHT; dir++ {
+				door := game.rooms[loc.y][loc.x].getDoor(dir)
+				neighbour := game.getNeighbour(loc.x, loc.y, dir)
+				if !door.exists || door.locked || neighbour == nil || reached[neighbour.loc] {
+					continue
+				}
+				reached[neighbour.loc] = true
+				queue = append(queue, neighbour.loc)
+			}
+		}
+		if rooms := game.width() * game.height(); int64(len(reached)) != rooms {
+			t.Errorf("seed %d: only %d of the %d rooms can be reached without keys", seed, len(reached), rooms)
+		}
+	}
+}
@@ -1,7 +1,5 @@
 package main
 
-import "math/rand"
-
 type EnemyType int8
 
 const (
@@ -9,18 +7,22 @@ const (
 	WARRIOR  EnemyType = iota
 	BRUTE    EnemyType = iota
 	E_MYSTIC EnemyType = iota
+	WARLORD  EnemyType = iota // the boss, only found in the Boss Room
 )
 
 const (
 	BaseEnemyMinDamage = 5
 	BaseEnemyMaxDamage = 10
+	BaseEnemyDefense   = 0
 )
 
 type Enemy struct {
 	eType       EnemyType
 	health      float64
+	maxHealth   float64
 	strength    float64
-	turnCounter int
+	turnCounter int // turns the enemy has taken, drives its behaviour
+	statuses    Statuses
 }
 
 func NewEnemy(eType EnemyType) *Enemy {
@@ -39,14 +41,31 @@ func NewEnemy(eType EnemyType) *Enemy {
 	case E_MYSTIC:
 		e.health = 50
 		e.strength = 1.5
+	case WARLORD:
+		e.health = 300
+		e.strength = 1.5
 	}
+	e.maxHealth = e.health
 	e.turnCounter = 0
 	return e
 }
 
-func (e *Enemy) getDamageFromAttack() float64 {
-	min, max := BaseEnemyMinDamage*e.strength, BaseEnemyMaxDamage*e.strength
-	return min + rand.Float64()*(max-min)
+// isAlive is false for dead enemies and empty enemy slots.
+func (e *Enemy) isAlive() bool {
+	return e != nil && e.health > 0
+}
+
+func getAllEnemyTypes() [5]EnemyType {
+	return [5]EnemyType{PEON, WARRIOR, BRUTE, E_MYSTIC, WARLORD}
+}
+
+func getEnemyTypeFromName(name string) EnemyType {
+	for _, eType := range getAllEnemyTypes() {
+		if getEnemyNameFromType(eType) == name {
+			return eType
+		}
+	}
+	return -1
 }
 
 func getEnemyNameFromType(eType EnemyType) string {
@@ -59,6 +78,8 @@ func getEnemyNameFromType(eType EnemyType) string {
 		return "Brute"
 	case E_MYSTIC:
 		return "Mystic"
+	case WARLORD:
+		return "Warlord"
 	default:
 		return "Invalid"
 	}
@@ -0,0 +1,713 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The engine is the headless side of the game. Every decision the player can
+// make is an Action, and performing one returns a Result describing what
+// happened. The console menus are just one client of this, tests and bots can
+// drive a Game the same way without touching stdin or stdout.
+
+type ActionType int8
+
+const (
+	ActionMove     ActionType = iota
+	ActionLoot     ActionType = iota
+	ActionUseItem  ActionType = iota
+	ActionEquip    ActionType = iota
+	ActionDiscard  ActionType = iota
+	ActionAttack   ActionType = iota
+	ActionRun      ActionType = iota
+	ActionUnlock   ActionType = iota
+	ActionInteract ActionType = iota
+	ActionSwapMove ActionType = iota
+	ActionUnequip  ActionType = iota
+	ActionBuy      ActionType = iota
+	ActionBuyBack  ActionType = iota
+)
+
+// Action is a single player decision. dir is used by ActionMove, ActionRun
+// and ActionUnlock. index is the item slot for the item actions, the move
+// index for ActionAttack and the ware for ActionBuy and ActionBuyBack, which
+// buy from the merchant's stock or buy-back list. target is the index of the
+// enemy in the room that ActionAttack and a damage item used with
+// ActionUseItem hit, moves that hit every enemy ignore it. ActionSwapMove puts
+// the known move at target into loadout slot index, and ActionUnequip takes
+// off the item of the type index. chests lists the indexes of the locked
+// chests an ActionUseItem key unlocks, nil unlocks the first ones it has
+// charges for.
+type Action struct {
+	aType  ActionType
+	dir    Direction
+	index  int
+	target int
+	chests []int
+}
+
+func moveAction(dir Direction) Action {
+	return Action{aType: ActionMove, dir: dir}
+}
+
+func lootAction() Action {
+	return Action{aType: ActionLoot}
+}
+
+func useItemAction(slot, target int) Action {
+	return Action{aType: ActionUseItem, index: slot, target: target}
+}
+
+func unlockChestsAction(slot int, chests []int) Action {
+	return Action{aType: ActionUseItem, index: slot, chests: chests}
+}
+
+func equipAction(slot int) Action {
+	return Action{aType: ActionEquip, index: slot}
+}
+
+func unequipAction(iType ItemType) Action {
+	return Action{aType: ActionUnequip, index: int(iType)}
+}
+
+func discardAction(slot int) Action {
+	return Action{aType: ActionDiscard, index: slot}
+}
+
+func attackAction(move, target int) Action {
+	return Action{aType: ActionAttack, index: move, target: target}
+}
+
+func runAction(dir Direction) Action {
+	return Action{aType: ActionRun, dir: dir}
+}
+
+func unlockAction(dir Direction) Action {
+	return Action{aType: ActionUnlock, dir: dir}
+}
+
+func interactAction() Action {
+	return Action{aType: ActionInteract}
+}
+
+func swapMoveAction(slot, known int) Action {
+	return Action{aType: ActionSwapMove, index: slot, target: known}
+}
+
+func buyAction(ware int) Action {
+	return Action{aType: ActionBuy, index: ware}
+}
+
+func buyBackAction(ware int) Action {
+	return Action{aType: ActionBuyBack, index: ware}
+}
+
+type EventType int8
+
+const (
+	EventEnteredRoom    EventType = iota
+	EventEncounter      EventType = iota
+	EventLooted         EventType = iota
+	EventInventoryFull  EventType = iota
+	EventChestsUnlocked EventType = iota
+	EventEquipped       EventType = iota
+	EventDiscarded      EventType = iota
+	EventPlayerAttack   EventType = iota
+	EventEnemyAttack    EventType = iota
+	EventEnemyDefeated  EventType = iota
+	EventRanAway        EventType = iota
+	EventRunFailed      EventType = iota
+	EventPlayerDied     EventType = iota
+	EventDoorUnlocked   EventType = iota
+	EventHealed         EventType = iota
+	EventEnemyFled      EventType = iota
+	EventEnemyCharging  EventType = iota
+	EventEnemyBlocking  EventType = iota
+	EventEnemyHealed    EventType = iota
+	EventGainedXP       EventType = iota
+	EventLevelUp        EventType = iota
+	EventLearnedMove    EventType = iota
+	EventSwappedMove    EventType = iota
+	EventStatusApplied  EventType = iota
+	EventStatusDamage   EventType = iota
+	EventStatusHealed   EventType = iota
+	EventStatusExpired  EventType = iota
+	EventStunned        EventType = iota
+	EventVictory        EventType = iota
+	EventRested         EventType = iota
+	EventFreedPrisoners EventType = iota
+	EventInventoryGrown EventType = iota
+	EventUnequipped     EventType = iota
+	EventLifesteal      EventType = iota
+	EventGainedGold     EventType = iota
+	EventBought         EventType = iota
+	EventSold           EventType = iota
+)
+
+// Event is something that happened while performing an action. amount holds
+// the relevant number for the event (damage done, chests looted, ...) and
+// message is a human readable description for clients that just print them.
+type Event struct {
+	eType   EventType
+	amount  float64
+	message string
+}
+
+type Result struct {
+	turnConsumed bool
+	events       []Event
+	err          error
+}
+
+func (res *Result) addEvent(eType EventType, amount float64, format string, args ...interface{}) {
+	res.events = append(res.events, Event{eType, amount, fmt.Sprintf(format, args...)})
+}
+
+var (
+	errPlayerDead      = errors.New("the player is dead")
+	errGameWon         = errors.New("the game has been won")
+	errUnknownAction   = errors.New("unknown action")
+	errNotExploring    = errors.New("that can only be done while exploring")
+	errNotFighting     = errors.New("that can only be done while fighting")
+	errBadDirection    = errors.New("invalid direction")
+	errNoDoor          = errors.New("there is no door in that direction")
+	errDoorLocked      = errors.New("that door is locked, use a key to open it")
+	errOffMap          = errors.New("that door leads off the map")
+	errDoorNotLocked   = errors.New("that door is not locked")
+	errNoKey           = errors.New("you do not have a key")
+	errNothingToLoot   = errors.New("there are no unlocked chests with items or gold in this room")
+	errInventoryFull   = errors.New("your inventory is full, discard an item to free up space")
+	errTooHeavy        = errors.New("that is too heavy to carry, discard items to lighten your load")
+	errInventoryMaxed  = errors.New("your inventory cannot get any bigger")
+	errInvalidSlot     = errors.New("selected index does not exist")
+	errEmptySlot       = errors.New("there is no item in that slot")
+	errNotUseable      = errors.New("the selected item is not a useable item")
+	errNotEquipable    = errors.New("the selected item is not an equipable item")
+	errNothingEquipped = errors.New("there is nothing equipped there")
+	errNoLockedChests  = errors.New("there are no locked chests in this room, this item cannot be used")
+	errNotLockedChest  = errors.New("there is no locked chest there")
+	errNotEnoughCharge = errors.New("this key cannot unlock that many chests")
+	errFullHealth      = errors.New("you are already at full health")
+	errInvalidMove     = errors.New("selected move does not exist")
+	errOnCooldown      = errors.New("that move is on cooldown")
+	errInvalidTarget   = errors.New("there is no living enemy to target there")
+	errNoInteraction   = errors.New("there is nothing special to do in this room")
+	errInteractionUsed = errors.New("you have already done that in this room")
+	errNothingToLearn  = errors.New("there are no moves left to learn")
+	errUnknownMove     = errors.New("you do not know that move")
+	errMoveInLoadout   = errors.New("that move is already in your loadout")
+	errNoMerchant      = errors.New("there is no merchant in this room")
+	errNoSuchWare      = errors.New("the merchant does not have that")
+	errNotEnoughGold   = errors.New("you do not have enough gold for that")
+)
+
+// perform runs one player action and, if the player is fighting and the action
+// took their turn, the turns of the enemies after it. The player's statuses
+// tick at the end of the action and at the start of their next turn.
+func (game *Game) perform(action Action) *Result {
+	return game.player.perform(action)
+}
+
+func (p *Player) perform(action Action) *Result {
+	res := new(Result)
+	if p.state == Dead {
+		res.err = errPlayerDead
+		return res
+	}
+	if p.state == Won {
+		res.err = errGameWon
+		return res
+	}
+
+	// var reset
+	p.movedLast = false
+	if p.currentRoom.getNumEnemiesAlive() > 0 {
+		p.state = Fighting
+	} else if p.state == Fighting {
+		p.state = Exploring
+	}
+	fighting := p.state == Fighting
+
+	switch action.aType {
+	case ActionMove:
+		p.doMove(action.dir, res)
+	case ActionLoot:
+		p.doLoot(res)
+	case ActionUseItem:
+		p.doUseItem(action.index, action.target, action.chests, res)
+	case ActionEquip:
+		p.doEquip(action.index, res)
+	case ActionDiscard:
+		p.doDiscard(action.index, res)
+	case ActionAttack:
+		p.doAttack(action.index, action.target, res)
+	case ActionRun:
+		p.doRun(action.dir, res)
+	case ActionUnlock:
+		p.doUnlock(action.dir, res)
+	case ActionInteract:
+		p.doInteract(res)
+	case ActionSwapMove:
+		p.doSwapMove(action.index, action.target, res)
+	case ActionUnequip:
+		p.doUnequip(ItemType(action.index), res)
+	case ActionBuy:
+		p.doBuy(action.index, false, res)
+	case ActionBuyBack:
+		p.doBuy(action.index, true, res)
+	default:
+		res.err = errUnknownAction
+	}
+
+	if res.err != nil || !res.turnConsumed {
+		return res
+	}
+
+	if p.state == Won {
+		return res
+	}
+	p.endTurn(res)
+	if p.state == Dead {
+		return res
+	}
+	if p.movedLast {
+		p.enterRoom(res)
+	} else if fighting {
+		p.enemyTurn(res)
+	}
+	if p.state == Won {
+		return res
+	}
+	p.startTurn(res)
+	return res
+}
+
+func (p *Player) enterRoom(res *Result) {
+	p.currentRoom = &p.game.rooms[p.loc.y][p.loc.x]
+	p.game.visitRoom(p.loc.x, p.loc.y)
+	res.addEvent(EventEnteredRoom, 0, "You have entered a new room")
+	if DEBUG_MODE {
+		p.debugPrintLoc()
+	}
+	if p.currentRoom.getNumEnemiesAlive() > 0 {
+		p.state = Fighting
+		res.addEvent(EventEncounter, float64(p.currentRoom.getNumEnemiesAlive()), "\nYou have Encountered an Enemy!\nPrepare to Fight!")
+	}
+}
+
+// enemyTurn has every living enemy in the room take its turn, in order. Their
+// statuses tick at the start and the end of it, a stunned enemy does nothing
+// in between.
+func (p *Player) enemyTurn(res *Result) {
+	for _, enemy := range p.currentRoom.enemies {
+		if !enemy.isAlive() {
+			continue
+		}
+		stunned := enemy.statuses.tick(TICK_START, enemy.getWho(), &enemy.health, enemy.maxHealth, res)
+		if !enemy.isAlive() {
+			p.checkDefeated(enemy, res)
+			continue
+		}
+
+		if !stunned {
+			enemy.turnCounter++
+			getEnemyBehaviour(enemy.eType)(enemy, p, res)
+		}
+
+		if p.health <= 0 {
+			p.state = Dead
+			res.addEvent(EventPlayerDied, 0, "It appears that the enemy killed you.")
+			return
+		}
+		enemy.statuses.tick(TICK_END, enemy.getWho(), &enemy.health, enemy.maxHealth, res)
+	}
+	// the last enemy may have fled
+	if p.currentRoom.getNumEnemiesAlive() == 0 {
+		p.endFight()
+	}
+}
+
+func (p *Player) doMove(dir Direction, res *Result) {
+	if p.state != Exploring {
+		res.err = errNotExploring
+		return
+	}
+	dest, err := p.game.getDestination(*p.loc, dir)
+	if err != nil {
+		res.err = err
+		return
+	}
+
+	*p.loc = dest.loc
+	p.movedLast = true
+	res.turnConsumed = true
+}
+
+func (p *Player) doLoot(res *Result) {
+	if p.state != Exploring {
+		res.err = errNotExploring
+		return
+	}
+	numUnlockedChest := p.currentRoom.getNumLootableChests()
+	if numUnlockedChest == 0 {
+		res.err = errNothingToLoot
+		return
+	}
+	count, left, gold := 0, 0, 0
+	var reason error
+	for _, chest := range p.currentRoom.chests {
+		if chest == nil || chest.locked {
+			continue
+		}
+		// gold weighs nothing, it is taken even if the item is left
+		gold += chest.gold
+		chest.gold = 0
+		if chest.item == nil {
+			continue
+		}
+		if err := p.inventory.addItem(chest.item); err != nil {
+			// a later item may still go on a stack or weigh less
+			reason = err
+			left++
+			continue
+		}
+		chest.item = nil
+		count++
+	}
+	if count == 0 && gold == 0 {
+		res.err = reason
+		return
+	}
+	p.gainGold(gold, res)
+	if reason == errTooHeavy {
+		res.addEvent(EventInventoryFull, float64(left), "%d chests were left, they are too heavy to carry. Discard items to lighten your load", left)
+	} else if left > 0 {
+		res.addEvent(EventInventoryFull, float64(left), "%d chests were left, your inventory has no space for them. Discard items to free inventory space", left)
+	}
+	if count == 1 {
+		res.addEvent(EventLooted, 1, "Looted 1 chest")
+	} else if count > 1 {
+		res.addEvent(EventLooted, float64(count), "Looted %d chests", count)
+	}
+	res.turnConsumed = true
+}
+
+// checkSlot returns the item at the given slot or sets the matching error on res.
+func (p *Player) checkSlot(slot int, res *Result) *Item {
+	if slot < 0 || slot >= p.inventory.size() {
+		res.err = errInvalidSlot
+		return nil
+	}
+	item := p.inventory.itemSlots[slot]
+	if item == nil {
+		res.err = errEmptySlot
+	}
+	return item
+}
+
+func (p *Player) doUseItem(slot, target int, chests []int, res *Result) {
+	if p.checkSlot(slot, res) == nil {
+		return
+	}
+	item, ok := p.inventory.isUseable(slot)
+	if !ok {
+		res.err = errNotUseable
+		return
+	}
+
+	switch item.iType {
+	case KEY:
+		p.useKey(slot, chests, res)
+		return
+	case HEALTH:
+		if p.health >= p.getMaxHealth() {
+			res.err = errFullHealth
+			return
+		}
+		healed := p.healPlayer(item.effect)
+		p.inventory.removeOne(slot)
+		res.addEvent(EventHealed, healed, "You healed %.2f health.", healed)
+		if item.status != nil {
+			p.statuses.add(item.status.sType, item.status.power, item.status.turns, "You", res)
+		}
+	case INSTANT_DAMAGE:
+		if p.state != Fighting {
+			res.err = errNotFighting
+			return
+		}
+		enemy := p.currentRoom.getEnemy(target)
+		if enemy == nil {
+			res.err = errInvalidTarget
+			return
+		}
+		// bombs hit just as hard whoever throws them, only the defense counts
+		damage := calcDamage(item.effect, 1, enemy.getDefense())
+		res.addEvent(EventPlayerAttack, damage, "\nYour %s did %.2f damage to the %s.", item.getName(p.game.balance), damage, getEnemyNameFromType(enemy.eType))
+		enemy.health -= damage
+		p.inventory.removeOne(slot)
+		if item.status != nil && enemy.isAlive() {
+			enemy.statuses.add(item.status.sType, item.status.power, item.status.turns, enemy.getWho(), res)
+		}
+		p.checkDefeated(enemy, res)
+	case BAG:
+		added := p.inventory.grow(int(item.effect))
+		if added == 0 {
+			res.err = errInventoryMaxed
+			return
+		}
+		p.inventory.removeOne(slot)
+		res.addEvent(EventInventoryGrown, float64(added), "Your inventory grew by %d slots to %d.", added, p.inventory.size())
+	default:
+		fmt.Println("Impossible case: Default case from inv.isUseable")
+		res.err = errNotUseable
+		return
+	}
+	p.itemsUsed++
+	res.turnConsumed = true
+}
+
+// useKey unlocks the chests with the key at slot, a charge each. A key with no
+// charges left is used up.
+func (p *Player) useKey(slot int, chests []int, res *Result) {
+	key := p.inventory.itemSlots[slot]
+	locked := p.currentRoom.getLockedChestSlots()
+	if len(locked) == 0 {
+		res.err = errNoLockedChests
+		return
+	}
+	if chests == nil {
+		chests = locked[:minInt(len(locked), int(key.effect))]
+	}
+	picked := make(map[int]bool, len(chests))
+	for _, index := range chests {
+		if index < 0 || index >= len(p.currentRoom.chests) || p.currentRoom.chests[index] == nil || !p.currentRoom.chests[index].locked || picked[index] {
+			res.err = errNotLockedChest
+			return
+		}
+		picked[index] = true
+	}
+	if len(chests) == 0 {
+		res.err = errNotLockedChest
+		return
+	}
+	if float64(len(chests)) > key.effect {
+		res.err = errNotEnoughCharge
+		return
+	}
+
+	left, err := p.inventory.spendCharges(slot, float64(len(chests)))
+	if err != nil {
+		res.err = err
+		return
+	}
+	for _, index := range chests {
+		p.currentRoom.chests[index].locked = false
+	}
+	p.itemsUsed += len(chests)
+	res.turnConsumed = true
+
+	unlocked := "Unlocked 1 chest"
+	if len(chests) > 1 {
+		unlocked = fmt.Sprintf("Unlocked %d chests", len(chests))
+	}
+	switch {
+	case left < 1:
+		res.addEvent(EventChestsUnlocked, float64(len(chests)), "%s, the key was used up", unlocked)
+	case left < 2:
+		res.addEvent(EventChestsUnlocked, float64(len(chests)), "%s\nThis key can unlock %3.1f more locked chest", unlocked, left)
+	default:
+		res.addEvent(EventChestsUnlocked, float64(len(chests)), "%s\nThis key can unlock %3.1f more locked chests", unlocked, left)
+	}
+}
+
+func (p *Player) doEquip(slot int, res *Result) {
+	if p.checkSlot(slot, res) == nil {
+		return
+	}
+	item, ok := p.inventory.isEquipable(slot)
+	if !ok {
+		res.err = errNotEquipable
+		return
+	}
+
+	// swap so the previously equipped item (if any) takes the slot of the new one
+	p.inventory.itemSlots[slot] = p.inventory.getEquipped(item.iType)
+	p.inventory.equipped[item.iType] = item
+	p.clampHealth()
+	res.addEvent(EventEquipped, item.effect, "Equipped item: Type=%-7s Effect=%7.3f", getStringFromItemType(item.iType), item.effect)
+	res.turnConsumed = true
+}
+
+// doDiscard throws the stack at slot away, or sells it in a Merchant room.
+func (p *Player) doDiscard(slot int, res *Result) {
+	item := p.checkSlot(slot, res)
+	if item == nil {
+		return
+	}
+	if p.currentRoom.rType == MERCHANT {
+		p.sell(slot, res)
+		return
+	}
+	p.inventory.itemSlots[slot] = nil
+	if item.count > 1 {
+		res.addEvent(EventDiscarded, item.effect, "Discarded %d items", item.count)
+	} else {
+		res.addEvent(EventDiscarded, item.effect, "Discarded item")
+	}
+	res.turnConsumed = true
+}
+
+func (p *Player) doAttack(index, target int, res *Result) {
+	if p.state != Fighting {
+		res.err = errNotFighting
+		return
+	}
+	if index < 0 || index >= len(p.moves) {
+		res.err = errInvalidMove
+		return
+	}
+	move := p.moves[index]
+	if move.cooldown > 0 {
+		res.err = errOnCooldown
+		return
+	}
+	var targets []*Enemy
+	if move.hitsAll {
+		for _, slot := range p.currentRoom.getLivingEnemySlots() {
+			targets = append(targets, p.currentRoom.enemies[slot])
+		}
+	} else if move.needsTarget() {
+		enemy := p.currentRoom.getEnemy(target)
+		if enemy == nil {
+			res.err = errInvalidTarget
+			return
+		}
+		targets = append(targets, enemy)
+	}
+
+	for _, enemy := range targets {
+		for hit := 0; hit < move.hits && enemy.isAlive(); hit++ {
+			min, max := move.getDamage(p.getWeaponDamage())
+			damage := rollDamage(p.game.rng, min, max, p.getStrength(), enemy.getDefense())
+			res.addEvent(EventPlayerAttack, damage, "\nYour %s did %.2f damage to the %s.", move.name, damage, getEnemyNameFromType(enemy.eType))
+			enemy.health -= damage
+			if steal := p.inventory.getEquipStats().Lifesteal; steal > 0 && damage > 0 {
+				if healed := p.healPlayer(damage * steal); healed > 0 {
+					res.addEvent(EventLifesteal, healed, "You drained %.2f health.", healed)
+				}
+			}
+		}
+		if enemy.isAlive() {
+			move.applyEffect(enemy, res)
+		}
+	}
+	move.applySelfEffect(p, res)
+
+	for _, temp := range p.moves {
+		if temp.cooldown > 0 {
+			temp.cooldown--
+		}
+	}
+
+	if move.maxCooldown > 0 {
+		move.cooldown = move.maxCooldown
+	}
+
+	for _, enemy := range targets {
+		p.checkDefeated(enemy, res)
+	}
+	res.turnConsumed = true
+}
+
+// checkDefeated reports enemy as defeated if it has no health left and ends
+// the fight once no enemy in the room is left standing.
+func (p *Player) checkDefeated(enemy *Enemy, res *Result) {
+	if enemy.health > 0.0 {
+		return
+	}
+	res.addEvent(EventEnemyDefeated, 0, "You defeated the %s", getEnemyNameFromType(enemy.eType))
+	p.kills++
+	p.gainXP(p.game.balance.EnemyXP[getEnemyNameFromType(enemy.eType)], res)
+	p.gainGold(p.game.balance.EnemyGold[getEnemyNameFromType(enemy.eType)], res)
+	if p.currentRoom.getNumEnemiesAlive() == 0 {
+		p.endFight()
+	}
+	if enemy.eType == WARLORD {
+		p.state = Won
+		res.addEvent(EventVictory, 0, "\nThe %s has fallen, you have won!", getEnemyNameFromType(enemy.eType))
+	}
+}
+
+func (p *Player) endFight() {
+	if p.state == Fighting {
+		p.state = Exploring
+	}
+	for _, temp := range p.moves {
+		temp.cooldown = 0
+	}
+}
+
+func (p *Player) doRun(dir Direction, res *Result) {
+	if p.state != Fighting {
+		res.err = errNotFighting
+		return
+	}
+	destRoom, err := p.game.getDestination(*p.loc, dir)
+	if err != nil {
+		res.err = err
+		return
+	}
+
+	from := p.game.rng.Float64()
+	to := p.game.rng.Float64()
+	res.turnConsumed = true
+	if !(p.currentRoom.canRunFrom(p.game.balance, from) && destRoom.canRunTo(p.game.balance, to)) {
+		res.addEvent(EventRunFailed, 0, "\nCouldnt get away!")
+		return
+	}
+	*p.loc = destRoom.loc
+	p.state = Exploring
+	p.movedLast = true
+	res.addEvent(EventRanAway, 0, "Got away safely")
+}
+
+// doUnlock spends one charge of the first key in the inventory to unlock the
+// door in dir, from both sides. Used up keys are removed.
+func (p *Player) doUnlock(dir Direction, res *Result) {
+	if p.state != Exploring {
+		res.err = errNotExploring
+		return
+	}
+	if dir < UP || dir > RIGHT {
+		res.err = errBadDirection
+		return
+	}
+	door := p.currentRoom.getDoor(dir)
+	if !door.exists {
+		res.err = errNoDoor
+		return
+	}
+	if !door.locked {
+		res.err = errDoorNotLocked
+		return
+	}
+	slot := p.inventory.findKey()
+	if slot == -1 {
+		res.err = errNoKey
+		return
+	}
+
+	left, err := p.inventory.spendCharges(slot, 1)
+	if err != nil {
+		res.err = err
+		return
+	}
+	p.itemsUsed++
+	p.game.openDoor(p.loc.x, p.loc.y, dir)
+	if left < 1 {
+		res.addEvent(EventDoorUnlocked, 1, "Unlocked the door, the key was used up")
+	} else {
+		res.addEvent(EventDoorUnlocked, 1, "Unlocked the door\nThis key can unlock %3.1f more", left)
+	}
+	res.turnConsumed = true
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The engine is meant to be driven without the console, these tests drive it
+// the way a bot would and pin down the Results it hands back.
+
+const (
+	testSeed   = 42
+	testRadius = 4
+)
+
+func newTestGame(t *testing.T) *Game {
+	t.Helper()
+	return newGame(testSeed, testRadius, defaultBalance())
+}
+
+// clearRoom empties the room the player is in and the one to the right of it
+// and opens the door between them, so tests start from a known spot.
+func clearRoom(game *Game) (here, right *Room) {
+	p := game.player
+	here = p.currentRoom
+	right = game.getNeighbour(p.loc.x, p.loc.y, RIGHT)
+	for _, room := range []*Room{here, right} {
+		room.chests = nil
+		room.enemies = nil
+	}
+	game.openDoor(p.loc.x, p.loc.y, RIGHT)
+	return here, right
+}
+
+// addEnemy puts an enemy with the given health in the player's room and starts
+// the fight.
+func addEnemy(game *Game, eType EnemyType, health float64) *Enemy {
+	enemy := NewEnemy(eType)
+	enemy.health = health
+	game.player.currentRoom.enemies = append(game.player.currentRoom.enemies, enemy)
+	game.player.state = Fighting
+	return enemy
+}
+
+func eventTypes(res *Result) []EventType {
+	var types []EventType
+	for _, event := range res.events {
+		types = append(types, event.eType)
+	}
+	return types
+}
+
+func hasEvent(res *Result, eType EventType) bool {
+	for _, event := range res.events {
+		if event.eType == eType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInvalidActions(t *testing.T) {
+	game := newTestGame(t)
+	clearRoom(game)
+
+	tests := []struct {
+		name   string
+		action Action
+		err    error
+	}{
+		{"unknown action", Action{aType: -1}, errUnknownAction},
+		{"bad direction", moveAction(Direction(9)), errBadDirection},
+		{"loot an empty room", lootAction(), errNothingToLoot},
+		{"attack while exploring", attackAction(0, 0), errNotFighting},
+		{"run while exploring", runAction(RIGHT), errNotFighting},
+		{"use an empty slot", useItemAction(0, 0), errEmptySlot},
+		{"use a slot past the end", useItemAction(-1, 0), errInvalidSlot},
+		{"unlock a door that is open", unlockAction(RIGHT), errDoorNotLocked},
+		{"buy outside a shop", buyAction(0), errNoMerchant},
+	}
+	for _, test := range tests {
+		res := game.perform(test.action)
+		if res.err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, res.err, test.err)
+		}
+		if res.turnConsumed {
+			t.Errorf("%s: a failed action consumed the turn", test.name)
+		}
+	}
+}
+
+func TestMove(t *testing.T) {
+	game := newTestGame(t)
+	_, right := clearRoom(game)
+	p := game.player
+
+	res := game.perform(moveAction(RIGHT))
+	if res.err != nil {
+		t.Fatalf("move: %v", res.err)
+	}
+	if !res.turnConsumed {
+		t.Error("move did not consume the turn")
+	}
+	if !reflect.DeepEqual(eventTypes(res), []EventType{EventEnteredRoom}) {
+		t.Errorf("move: got events %v, want only EventEnteredRoom", eventTypes(res))
+	}
+	if p.currentRoom != right || *p.loc != right.loc {
+		t.Errorf("player is at %+v, want %+v", *p.loc, right.loc)
+	}
+	if !right.visited {
+		t.Error("the room moved into is not visited")
+	}
+}
+
+func TestLoot(t *testing.T) {
+	game := newTestGame(t)
+	here, _ := clearRoom(game)
+	p := game.player
+	here.chests = []*Chest{
+		{item: NewItem(HEALTH, 20), gold: 7},
+		{locked: true, item: NewItem(KEY, 1)},
+	}
+
+	res := game.perform(lootAction())
+	if res.err != nil {
+		t.Fatalf("loot: %v", res.err)
+	}
+	if !res.turnConsumed {
+		t.Error("loot did not consume the turn")
+	}
+	if !hasEvent(res, EventLooted) || !hasEvent(res, EventGainedGold) {
+		t.Errorf("loot: got events %v, want EventLooted and EventGainedGold", eventTypes(res))
+	}
+	if p.gold != 7 || p.inventory.slotsUsed() != 1 {
+		t.Errorf("got %d gold and %d items, want 7 and 1", p.gold, p.inventory.slotsUsed())
+	}
+	if here.chests[1].item == nil {
+		t.Error("the locked chest was looted")
+	}
+	if res := game.perform(lootAction()); res.err != errNothingToLoot {
+		t.Errorf("looting twice: got error %v, want %v", res.err, errNothingToLoot)
+	}
+}
+
+func TestAttack(t *testing.T) {
+	game := newTestGame(t)
+	clearRoom(game)
+	p := game.player
+	enemy := addEnemy(game, PEON, 0.01)
+
+	res := game.perform(attackAction(0, 0))
+	if res.err != nil {
+		t.Fatalf("attack: %v", res.err)
+	}
+	if !res.turnConsumed {
+		t.Error("attack did not consume the turn")
+	}
+	want := []EventType{EventPlayerAttack, EventEnemyDefeated, EventGainedXP, EventGainedGold}
+	if got := eventTypes(res); !reflect.DeepEqual(got, want) {
+		t.Errorf("attack: got events %v, want %v", got, want)
+	}
+	if enemy.isAlive() || p.state != Exploring || p.kills != 1 {
+		t.Errorf("the fight did not end: enemy health %v, state %v, kills %d", enemy.health, p.state, p.kills)
+	}
+
+	if res := game.perform(attackAction(0, 0)); res.err != errNotFighting {
+		t.Errorf("attacking after the fight: got error %v, want %v", res.err, errNotFighting)
+	}
+}
+
+func TestAttackErrors(t *testing.T) {
+	game := newTestGame(t)
+	clearRoom(game)
+	addEnemy(game, BRUTE, 1000)
+	addEnemy(game, PEON, 1000)
+
+	if res := game.perform(attackAction(len(game.player.moves), 0)); res.err != errInvalidMove {
+		t.Errorf("unknown move: got error %v, want %v", res.err, errInvalidMove)
+	}
+	if res := game.perform(attackAction(0, 5)); res.err != errInvalidTarget {
+		t.Errorf("missing target: got error %v, want %v", res.err, errInvalidTarget)
+	}
+
+	res := game.perform(attackAction(0, 1))
+	if res.err != nil {
+		t.Fatalf("attack: %v", res.err)
+	}
+	// both enemies get their turn after the player
+	if !hasEvent(res, EventPlayerAttack) {
+		t.Errorf("attack: got events %v, want EventPlayerAttack", eventTypes(res))
+	}
+	if game.player.currentRoom.enemies[1].health >= 1000 || game.player.currentRoom.enemies[0].health != 1000 {
+		t.Error("the attack did not hit the picked target only")
+	}
+}
+
+func TestRun(t *testing.T) {
+	game := newTestGame(t)
+	here, right := clearRoom(game)
+	p := game.player
+	game.balance.RunFromChances[getPrintStringFromRoomType(here.rType)] = 1
+	game.balance.RunToChances[getPrintStringFromRoomType(right.rType)] = 1
+	addEnemy(game, PEON, 1000)
+
+	res := game.perform(runAction(RIGHT))
+	if res.err != nil {
+		t.Fatalf("run: %v", res.err)
+	}
+	if !res.turnConsumed {
+		t.Error("run did not consume the turn")
+	}
+	want := []EventType{EventRanAway, EventEnteredRoom}
+	if got := eventTypes(res); !reflect.DeepEqual(got, want) {
+		t.Errorf("run: got events %v, want %v", got, want)
+	}
+	if p.currentRoom != right || p.state != Exploring {
+		t.Errorf("the player did not get away: at %+v, state %v", *p.loc, p.state)
+	}
+}
+
+func TestEveryRoomCanBeRunFrom(t *testing.T) {
+	balance := defaultBalance()
+	for _, rType := range getAllRoomTypes() {
+		name := getPrintStringFromRoomType(rType)
+		if balance.RunFromChances[name] <= 0 || balance.RunToChances[name] <= 0 {
+			t.Errorf("running from or to a %s never works", name)
+		}
+	}
+	delete(balance.RunFromChances, getPrintStringFromRoomType(BOSS))
+	if balance.validate() == nil {
+		t.Error("a balance without a Boss Room RunFromChances entry is valid")
+	}
+}
+
+func TestRunFailed(t *testing.T) {
+	game := newTestGame(t)
+	here, _ := clearRoom(game)
+	p := game.player
+	game.balance.RunFromChances[getPrintStringFromRoomType(here.rType)] = 0
+	addEnemy(game, PEON, 1000)
+
+	res := game.perform(runAction(RIGHT))
+	if res.err != nil {
+		t.Fatalf("run: %v", res.err)
+	}
+	if !res.turnConsumed || !hasEvent(res, EventRunFailed) {
+		t.Errorf("run: got events %v and turnConsumed %v, want EventRunFailed on a consumed turn", eventTypes(res), res.turnConsumed)
+	}
+	if p.currentRoom != here || p.state != Fighting {
+		t.Error("the player left the room after failing to run")
+	}
+}
+
+func TestDamageItemTarget(t *testing.T) {
+	game := newTestGame(t)
+	clearRoom(game)
+	p := game.player
+	first := addEnemy(game, BRUTE, 1000)
+	second := addEnemy(game, BRUTE, 1000)
+	first.health = 0
+	p.inventory.place(NewItem(INSTANT_DAMAGE, 20))
+
+	if res := game.perform(useItemAction(0, 0)); res.err != errInvalidTarget {
+		t.Errorf("bombing a dead enemy: got error %v, want %v", res.err, errInvalidTarget)
+	}
+	if res := game.perform(useItemAction(0, 2)); res.err != errInvalidTarget {
+		t.Errorf("bombing a missing enemy: got error %v, want %v", res.err, errInvalidTarget)
+	}
+	if p.inventory.itemSlots[0] == nil {
+		t.Fatal("a failed use spent the item")
+	}
+
+	res := game.perform(useItemAction(0, 1))
+	if res.err != nil {
+		t.Fatalf("use: %v", res.err)
+	}
+	if res.events[0].eType != EventPlayerAttack || second.health > 980 {
+		t.Errorf("the bomb did not hit the picked enemy: events %v, health %v", eventTypes(res), second.health)
+	}
+}
+
+func TestDamageItemDefense(t *testing.T) {
+	game := newTestGame(t)
+	clearRoom(game)
+	enemy := addEnemy(game, BRUTE, 1000)
+	enemy.statuses.add(STATUS_SHIELDED, 5, 3, enemy.getWho(), &Result{})
+	game.player.inventory.place(NewItem(INSTANT_DAMAGE, 20))
+	want := calcDamage(20, 1, enemy.getDefense())
+
+	res := game.perform(useItemAction(0, 0))
+	if res.err != nil {
+		t.Fatalf("use: %v", res.err)
+	}
+	if res.events[0].eType != EventPlayerAttack || res.events[0].amount != want {
+		t.Errorf("got event %v of %v damage, want EventPlayerAttack of %v", res.events[0].eType, res.events[0].amount, want)
+	}
+	if lost := 1000 - enemy.health; lost != want {
+		t.Errorf("the bomb took %v health, want %v after defense", lost, want)
+	}
+}
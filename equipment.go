@@ -0,0 +1,151 @@
+package main
+
+import "fmt"
+
+// Equipment. The player wears one item of each equipable type, and each type
+// raises one stat by its effect: weapons add raw damage to every move that does
+// damage, armor and helmets add defense, rings strength and amulets max health.
+
+// EquipStats is what equipped items add to the player's stats.
+type EquipStats struct {
+	Damage    float64
+	Defense   float64
+	Strength  float64
+	MaxHealth float64
+	Lifesteal float64 // share of the damage dealt healed
+}
+
+// add raises stat, one of getAllEquipStats, by power.
+func (s *EquipStats) add(stat string, power float64) {
+	switch stat {
+	case STAT_DAMAGE:
+		s.Damage += power
+	case STAT_DEFENSE:
+		s.Defense += power
+	case STAT_STRENGTH:
+		s.Strength += power
+	case STAT_MAX_HEALTH:
+		s.MaxHealth += power
+	case STAT_LIFESTEAL:
+		s.Lifesteal += power
+	}
+}
+
+// getEquipTypes lists the equipable item types, one slot each, in the order
+// the equipment screen shows them.
+func getEquipTypes() [5]ItemType {
+	return [5]ItemType{WEAPON, ARMOR, HELMET, RING, AMULET}
+}
+
+func isEquipType(iType ItemType) bool {
+	for _, equipType := range getEquipTypes() {
+		if equipType == iType {
+			return true
+		}
+	}
+	return false
+}
+
+// getEquipStats is what the item adds when equipped, nothing for an item that
+// cannot be.
+func (item *Item) getEquipStats() EquipStats {
+	var stats EquipStats
+	if item == nil {
+		return stats
+	}
+	switch item.iType {
+	case WEAPON:
+		stats.Damage = item.effect
+	case ARMOR, HELMET:
+		stats.Defense = item.effect
+	case RING:
+		stats.Strength = item.effect
+	case AMULET:
+		stats.MaxHealth = item.effect
+	default:
+		return stats
+	}
+	for _, affix := range item.affixes {
+		stats.add(affix.stat, affix.power)
+	}
+	return stats
+}
+
+// getEquipStats adds up what every equipped item adds.
+func (inv *Inventory) getEquipStats() EquipStats {
+	var total EquipStats
+	for _, item := range inv.equipped {
+		stats := item.getEquipStats()
+		total.Damage += stats.Damage
+		total.Defense += stats.Defense
+		total.Strength += stats.Strength
+		total.MaxHealth += stats.MaxHealth
+		total.Lifesteal += stats.Lifesteal
+	}
+	return total
+}
+
+// getEquipped returns the item equipped in the slot of iType, nil for none.
+func (inv *Inventory) getEquipped(iType ItemType) *Item {
+	return inv.equipped[iType]
+}
+
+// describeEquipStats is what the effect of the item adds for equipment
+// listings, its affixes left out.
+func (item *Item) describeEquipStats() string {
+	switch item.iType {
+	case WEAPON:
+		return fmt.Sprintf("+%.2f Damage", item.effect)
+	case ARMOR, HELMET:
+		return fmt.Sprintf("+%.2f Defense", item.effect)
+	case RING:
+		return fmt.Sprintf("+%.2f Strength", item.effect)
+	case AMULET:
+		return fmt.Sprintf("+%.2f Max Health", item.effect)
+	default:
+		return ""
+	}
+}
+
+func (inv *Inventory) printEquipment() {
+	for _, iType := range getEquipTypes() {
+		if item := inv.getEquipped(iType); item == nil {
+			fmt.Printf("%-7s: Empty\n", getStringFromItemType(iType))
+		} else {
+			fmt.Printf("%-7s: %s [%s] %s%s\n", getStringFromItemType(iType), item.getName(inv.balance), getStringFromRarity(item.rarity), item.describeEquipStats(), item.describeAffixes())
+		}
+	}
+	stats := inv.getEquipStats()
+	fmt.Printf("Total  : +%.2f Damage, +%.2f Defense, +%.2f Strength, +%.2f Max Health, %.0f%% Lifesteal\n", stats.Damage, stats.Defense, stats.Strength, stats.MaxHealth, stats.Lifesteal*100)
+}
+
+// doUnequip takes the item equipped in the slot of iType off and puts it in
+// the inventory, which needs a free slot for it.
+func (p *Player) doUnequip(iType ItemType, res *Result) {
+	if !isEquipType(iType) {
+		res.err = errNotEquipable
+		return
+	}
+	item := p.inventory.getEquipped(iType)
+	if item == nil {
+		res.err = errNothingEquipped
+		return
+	}
+	if p.inventory.room(item) < item.count {
+		res.err = errInventoryFull
+		return
+	}
+	delete(p.inventory.equipped, iType)
+	p.inventory.place(item)
+	p.clampHealth()
+	res.addEvent(EventUnequipped, item.effect, "Unequipped item: Type=%-7s Effect=%7.3f", getStringFromItemType(item.iType), item.effect)
+	res.turnConsumed = true
+}
+
+// clampHealth keeps health within max health after it went down, from taking
+// off an amulet.
+func (p *Player) clampHealth() {
+	if p.health > p.getMaxHealth() {
+		p.health = p.getMaxHealth()
+	}
+}
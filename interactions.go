@@ -0,0 +1,125 @@
+package main
+
+// Room interactions. Every RoomType can plug in one special thing to do in it,
+// offered when the room is explored. Most can only be done once per room, see
+// Room.interacted. Types without an entry have nothing special.
+
+type roomInteraction struct {
+	prompt   string // the question the console asks before doing it
+	once     bool   // it can only be done once per room
+	interact func(p *Player, res *Result)
+}
+
+var roomInteractions = map[RoomType]roomInteraction{
+	START: {
+		prompt:   "Your camp is here, nothing can follow you in. Would you like to rest until you are fully healed?",
+		interact: campInteraction,
+	},
+	GREAT_HALL: {
+		prompt:   "A fire is still burning in the hearth. Would you like to rest by it?",
+		once:     true,
+		interact: restInteraction,
+	},
+	DUNGEON: {
+		prompt:   "There are prisoners locked in the cells. Would you like to free them?",
+		once:     true,
+		interact: prisonerInteraction,
+	},
+	MYSTIC: {
+		prompt:   "There is a shrine in this room. Would you like to meditate at it to learn a move? It may also bless or curse you.",
+		once:     true,
+		interact: shrineInteraction,
+	},
+}
+
+// getInteraction returns the interaction of the room, ok is false if it has
+// none or it has been used up.
+func (r *Room) getInteraction() (interaction roomInteraction, ok bool) {
+	interaction, ok = roomInteractions[r.rType]
+	if !ok || (interaction.once && r.interacted) {
+		return interaction, false
+	}
+	return interaction, true
+}
+
+func (p *Player) doInteract(res *Result) {
+	if p.state != Exploring {
+		res.err = errNotExploring
+		return
+	}
+	interaction, ok := roomInteractions[p.currentRoom.rType]
+	if !ok {
+		res.err = errNoInteraction
+		return
+	}
+	if interaction.once && p.currentRoom.interacted {
+		res.err = errInteractionUsed
+		return
+	}
+	interaction.interact(p, res)
+	if res.err != nil {
+		return
+	}
+	p.currentRoom.interacted = true
+	res.turnConsumed = true
+}
+
+// campInteraction heals the player fully and rids them of every harmful
+// status. The start room is safe, so it can be done any number of times.
+func campInteraction(p *Player, res *Result) {
+	harmed := false
+	for _, status := range p.statuses {
+		harmed = harmed || isHarmful(status.sType)
+	}
+	if p.health >= p.getMaxHealth() && !harmed {
+		res.err = errFullHealth
+		return
+	}
+	healed := p.healPlayer(p.getMaxHealth())
+	kept := p.statuses[:0]
+	for _, status := range p.statuses {
+		if !isHarmful(status.sType) {
+			kept = append(kept, status)
+		}
+	}
+	p.statuses = kept
+	res.addEvent(EventRested, healed, "You rested at your camp and healed %.2f health.", healed)
+}
+
+// restInteraction heals Balance.RestHeal of the player's max health.
+func restInteraction(p *Player, res *Result) {
+	if p.health >= p.getMaxHealth() {
+		res.err = errFullHealth
+		return
+	}
+	healed := p.healPlayer(p.getMaxHealth() * p.game.balance.RestHeal)
+	res.addEvent(EventRested, healed, "You rested by the hearth and healed %.2f health.", healed)
+}
+
+// prisonerInteraction frees the prisoners of a dungeon. They are worth
+// Balance.PrisonerXP and leave a gift behind in an unlocked chest.
+func prisonerInteraction(p *Player, res *Result) {
+	balance := p.game.balance
+	res.addEvent(EventFreedPrisoners, balance.PrisonerXP, "You freed the prisoners. They left you a gift in thanks.")
+	depth := balance.getDifficulty().getDepth(p.game.getRing(p.loc.x, p.loc.y))
+	p.currentRoom.chests = append(p.currentRoom.chests, &Chest{item: rollLoot(p.currentRoom.rType, depth, balance, p.game.rng)})
+	p.gainXP(balance.PrisonerXP, res)
+}
+
+// shrineInteraction meditates at the shrine of a Mystical Room, which teaches
+// a move and blesses or curses the player with one of Balance.ShrineStatuses.
+func shrineInteraction(p *Player, res *Result) {
+	statuses := p.game.balance.ShrineStatuses
+	if !p.learnMove(res) && len(statuses) == 0 {
+		res.err = errNothingToLearn
+		return
+	}
+	if len(statuses) > 0 {
+		chances := make([]float64, len(statuses))
+		for i, status := range statuses {
+			chances[i] = status.Chance
+		}
+		status := statuses[rollChance(p.game.rng, chances)]
+		p.statuses.add(getStatusTypeFromString(status.Status), status.Power, status.Turns, "You", res)
+	}
+}
@@ -1,36 +1,148 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
-const inventorySize = 10
+// The inventory. It starts with Balance.InventorySize slots and bags add more,
+// up to Balance.MaxInventorySize. Items of the same type and tier stack in one
+// slot, up to the StackSizes of their type, and everything carried, the
+// equipment included, may weigh at most Balance.CarryWeight.
 
 type Inventory struct {
-	armorSlot *Item
-	itemSlots [inventorySize]*Item
+	equipped  map[ItemType]*Item // by type, see getEquipTypes
+	itemSlots []*Item            // one per slot, nil for an empty one
+	balance   *Balance
 }
 
-func NewInventory() *Inventory {
+func NewInventory(balance *Balance) *Inventory {
 	inv := new(Inventory)
+	inv.equipped = make(map[ItemType]*Item)
+	inv.itemSlots = make([]*Item, balance.InventorySize)
+	inv.balance = balance
 	return inv
 }
 
-func (inv *Inventory) addItem(item *Item) bool {
-	if inv.isFull() {
-		return false
+// size is how many slots the inventory has.
+func (inv *Inventory) size() int {
+	return len(inv.itemSlots)
+}
+
+// grow adds up to slots slots, never going past Balance.MaxInventorySize. It
+// returns how many were added.
+func (inv *Inventory) grow(slots int) int {
+	slots = maxInt(0, minInt(slots, inv.balance.MaxInventorySize-inv.size()))
+	inv.itemSlots = append(inv.itemSlots, make([]*Item, slots)...)
+	return slots
+}
+
+// stackSize is how many items of the type fit in one slot.
+func (inv *Inventory) stackSize(iType ItemType) int {
+	return maxInt(1, inv.balance.StackSizes[getStringFromItemType(iType)])
+}
+
+// room is how many more items like item fit in the inventory, filling up the
+// stacks it goes on first and then the empty slots.
+func (inv *Inventory) room(item *Item) int {
+	limit := inv.stackSize(item.iType)
+	room := 0
+	for _, current := range inv.itemSlots {
+		if current == nil {
+			room += limit
+		} else if current.stacksWith(item) {
+			room += maxInt(0, limit-current.count)
+		}
+	}
+	return room
+}
+
+// canAdd returns why item cannot be added, or nil if it can.
+func (inv *Inventory) canAdd(item *Item) error {
+	if inv.room(item) < item.count {
+		return errInventoryFull
+	}
+	if roundWeight(inv.weight()+item.getWeight(inv.balance)) > inv.balance.CarryWeight {
+		return errTooHeavy
+	}
+	return nil
+}
+
+// addItem puts the item on the stacks it goes on and the rest in empty slots.
+// Nothing is added if it does not all fit, canAdd says why.
+func (inv *Inventory) addItem(item *Item) error {
+	if err := inv.canAdd(item); err != nil {
+		return err
+	}
+	return inv.place(item)
+}
+
+// place puts the item away like addItem without checking that it fits. It
+// returns errInventoryFull if it runs out of slots, by then some of the item
+// may already be on stacks, so callers make sure there is room first.
+func (inv *Inventory) place(item *Item) error {
+	limit := inv.stackSize(item.iType)
+	for _, current := range inv.itemSlots {
+		if item.count == 0 {
+			return nil
+		}
+		if current != nil && current.stacksWith(item) {
+			moved := minInt(item.count, limit-current.count)
+			current.count += moved
+			item.count -= moved
+		}
+	}
+	for item.count > 0 {
+		index := inv.findFirstEmpty()
+		if index == -1 {
+			return errInventoryFull
+		}
+		if item.count <= limit {
+			inv.itemSlots[index] = item
+			return nil
+		}
+		inv.itemSlots[index] = item.split(limit)
 	}
-	index := inv.findFirstEmpty()
-	if index == -1 {
-		fmt.Println("THIS SHOULDNT BE POSSIBLE")
-		fmt.Println("addItem(*Item)bool: index == -1")
-		return false
+	return nil
+}
+
+// removeOne takes one item off the stack at index, emptying the slot when it
+// was the last.
+func (inv *Inventory) removeOne(index int) {
+	current := inv.itemSlots[index]
+	current.count--
+	if current.count < 1 {
+		inv.itemSlots[index] = nil
+	}
+}
+
+// spendCharges uses charges of one key of the stack at index and returns how
+// many that key has left. A key that is used up is removed, one that is not
+// leaves its stack as it is no longer the same tier, for which there must be
+// room.
+func (inv *Inventory) spendCharges(index int, charges float64) (left float64, err error) {
+	key := inv.itemSlots[index]
+	left = key.effect - charges
+	switch {
+	case left < 1:
+		inv.removeOne(index)
+	case key.count == 1:
+		key.effect = left
+	default:
+		used := key.split(1)
+		used.effect = left
+		if inv.room(used) < 1 {
+			key.count++
+			return 0, errInventoryFull
+		}
+		inv.place(used)
 	}
-	inv.itemSlots[index] = item
-	return true
+	return left, nil
 }
 
 // DO NOT CALL THIS METHOD ON A FULL INVENTORY
 func (inv *Inventory) findFirstEmpty() int {
-	for i := 0; i < inventorySize; i++ {
+	for i := 0; i < inv.size(); i++ {
 		if inv.itemSlots[i] == nil {
 			return i
 		}
@@ -38,18 +150,19 @@ func (inv *Inventory) findFirstEmpty() int {
 	return -1
 }
 
-func (inv *Inventory) isFull() bool {
-	for i := 0; i < inventorySize; i++ {
-		if inv.itemSlots[i] == nil {
-			return false
+// findKey returns the slot of the first key, or -1 if there are none.
+func (inv *Inventory) findKey() int {
+	for i := 0; i < inv.size(); i++ {
+		if inv.itemSlots[i] != nil && inv.itemSlots[i].iType == KEY {
+			return i
 		}
 	}
-	return true
+	return -1
 }
 
 func (inv *Inventory) slotsUsed() int {
 	count := 0
-	for i := 0; i < inventorySize; i++ {
+	for i := 0; i < inv.size(); i++ {
 		if inv.itemSlots[i] != nil {
 			count++
 		}
@@ -57,32 +170,29 @@ func (inv *Inventory) slotsUsed() int {
 	return count
 }
 
-func (inv *Inventory) slotsNotUsed() int {
-	count := 0
-	for i := 0; i < inventorySize; i++ {
-		if inv.itemSlots[i] == nil {
-			count++
-		}
+// weight is what everything carried weighs, the equipment included.
+func (inv *Inventory) weight() float64 {
+	total := 0.0
+	for _, current := range inv.equipped {
+		total += current.getWeight(inv.balance)
 	}
-	return count
+	for _, current := range inv.itemSlots {
+		total += current.getWeight(inv.balance)
+	}
+	return roundWeight(total)
 }
 
 func (inv *Inventory) isEquipable(index int) (*Item, bool) {
 	current := inv.itemSlots[index]
 	if current != nil {
-		switch current.iType {
-		case ARMOR:
-			return current, true
-		default:
-			return current, false
-		}
+		return current, isEquipType(current.iType)
 	}
 	return current, false
 }
 
 func (inv *Inventory) numEquipables() int {
 	count := 0
-	for i := 0; i < inventorySize; i++ {
+	for i := 0; i < inv.size(); i++ {
 		if _, ok := inv.isEquipable(i); ok {
 			count++
 		}
@@ -99,6 +209,8 @@ func (inv *Inventory) isUseable(index int) (*Item, bool) {
 		case HEALTH:
 			fallthrough
 		case INSTANT_DAMAGE:
+			fallthrough
+		case BAG:
 			return current, true
 		default:
 			return current, false
@@ -109,7 +221,7 @@ func (inv *Inventory) isUseable(index int) (*Item, bool) {
 
 func (inv *Inventory) numUseables() int {
 	count := 0
-	for i := 0; i < inventorySize; i++ {
+	for i := 0; i < inv.size(); i++ {
 		if _, ok := inv.isUseable(i); ok {
 			count++
 		}
@@ -117,31 +229,54 @@ func (inv *Inventory) numUseables() int {
 	return count
 }
 
+// getQuickSlots returns the slots of the items that can be used straight from
+// the combat menu, health and damage items.
+func (inv *Inventory) getQuickSlots() []int {
+	var slots []int
+	for i := 0; i < inv.size(); i++ {
+		current := inv.itemSlots[i]
+		if current != nil && (current.iType == HEALTH || current.iType == INSTANT_DAMAGE) {
+			slots = append(slots, i)
+		}
+	}
+	return slots
+}
+
 func (inv *Inventory) printFullInventory() {
 	fmt.Println("\nPrinting Inventory:")
 	inv.printItemInventory()
-	if inv.armorSlot == nil {
-		fmt.Printf("ArmorSlot: Empty\n")
-	} else {
-		fmt.Printf("ArmorSlot: DefenseBoost=%-7.3f\n", inv.armorSlot.effect)
-	}
+	fmt.Println("\nEquipment:")
+	inv.printEquipment()
 }
 
 func (inv *Inventory) printItemAt(index int) {
-	if index >= 0 && index < inventorySize {
+	if index >= 0 && index < inv.size() {
 		current := inv.itemSlots[index]
-		fmt.Printf("ItemSlot%1d: Type=%-7s Effect=%7.3f\n", index, getStringFromItemType(current.iType), current.effect)
+		fmt.Printf("ItemSlot%2d: %s\n", index, current.describe(inv.balance))
 	}
 }
 
 func (inv *Inventory) printItemInventory() {
-	fmt.Printf("Slots used:%2d/%-2d\n", inv.slotsUsed(), inventorySize)
-	for i := 0; i < inventorySize; i++ {
+	fmt.Printf("Slots used:%2d/%-2d Weight:%6.2f/%.2f\n", inv.slotsUsed(), inv.size(), inv.weight(), inv.balance.CarryWeight)
+	for i := 0; i < inv.size(); i++ {
 		current := inv.itemSlots[i]
 		if current == nil {
-			fmt.Printf("ItemSlot%1d: Empty\n", i)
+			fmt.Printf("ItemSlot%2d: Empty\n", i)
 		} else {
-			fmt.Printf("ItemSlot%1d: Type=%-7s Effect=%7.3f\n", i, getStringFromItemType(current.iType), current.effect)
+			inv.printItemAt(i)
 		}
 	}
 }
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// roundWeight keeps float error out of weight sums, so that a full load reads
+// as exactly Balance.CarryWeight.
+func roundWeight(weight float64) float64 {
+	return math.Round(weight*1000) / 1000
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// slot is what a test expects in an inventory slot, the zero slot for an empty
+// one.
+type slot struct {
+	iType  ItemType
+	effect float64
+	count  int
+}
+
+func newTestInventory(slots ...slot) *Inventory {
+	balance := defaultBalance()
+	balance.InventorySize = 2
+	inv := NewInventory(balance)
+	for i, s := range slots {
+		if s.count > 0 {
+			inv.itemSlots[i] = newStack(s)
+		}
+	}
+	return inv
+}
+
+func newStack(s slot) *Item {
+	item := NewItem(s.iType, s.effect)
+	item.count = s.count
+	return item
+}
+
+func getSlots(inv *Inventory) []slot {
+	slots := make([]slot, inv.size())
+	for i, item := range inv.itemSlots {
+		if item != nil {
+			slots[i] = slot{item.iType, item.effect, item.count}
+		}
+	}
+	return slots
+}
+
+func TestAddItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		slots []slot
+		add   slot
+		err   error
+		want  []slot
+	}{
+		{"into an empty slot", nil, slot{HEALTH, 20, 3}, nil, []slot{{HEALTH, 20, 3}, {}}},
+		{"onto a stack", []slot{{HEALTH, 20, 2}}, slot{HEALTH, 20, 3}, nil, []slot{{HEALTH, 20, 5}, {}}},
+		{"over a full stack", []slot{{HEALTH, 20, 4}}, slot{HEALTH, 20, 3}, nil, []slot{{HEALTH, 20, 5}, {HEALTH, 20, 2}}},
+		{"other tiers do not stack", []slot{{KEY, 3, 1}}, slot{KEY, 2, 1}, nil, []slot{{KEY, 3, 1}, {KEY, 2, 1}}},
+		{"past the last slot", []slot{{HEALTH, 20, 5}, {HEALTH, 20, 4}}, slot{HEALTH, 20, 2}, errInventoryFull, []slot{{HEALTH, 20, 5}, {HEALTH, 20, 4}}},
+		{"no slot for another tier", []slot{{KEY, 3, 1}, {KEY, 1, 1}}, slot{KEY, 2, 1}, errInventoryFull, []slot{{KEY, 3, 1}, {KEY, 1, 1}}},
+	}
+	for _, test := range tests {
+		inv := newTestInventory(test.slots...)
+		if err := inv.addItem(newStack(test.add)); err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+		if got := getSlots(inv); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got slots %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAddItemTooHeavy(t *testing.T) {
+	inv := newTestInventory()
+	inv.balance.CarryWeight = 1
+	if err := inv.addItem(newStack(slot{HEALTH, 20, 3})); err != errTooHeavy {
+		t.Errorf("got error %v, want %v", err, errTooHeavy)
+	}
+	if inv.slotsUsed() != 0 {
+		t.Error("an item that is too heavy was added")
+	}
+}
+
+func TestPlaceWithoutRoom(t *testing.T) {
+	inv := newTestInventory(slot{KEY, 3, 1}, slot{KEY, 1, 1})
+	if err := inv.place(NewItem(HEALTH, 20)); err != errInventoryFull {
+		t.Errorf("got error %v, want %v", err, errInventoryFull)
+	}
+}
+
+func TestSpendCharges(t *testing.T) {
+	tests := []struct {
+		name    string
+		slots   []slot
+		charges float64
+		left    float64
+		err     error
+		want    []slot
+	}{
+		{"some charges", []slot{{KEY, 3, 1}}, 1, 2, nil, []slot{{KEY, 2, 1}, {}}},
+		{"every charge", []slot{{KEY, 2, 1}}, 2, 0, nil, []slot{{}, {}}},
+		{"one key of a stack", []slot{{KEY, 3, 2}}, 1, 2, nil, []slot{{KEY, 3, 1}, {KEY, 2, 1}}},
+		{"onto a stack of its new tier", []slot{{KEY, 3, 2}, {KEY, 2, 1}}, 1, 2, nil, []slot{{KEY, 3, 1}, {KEY, 2, 2}}},
+		{"the last key of a stack", []slot{{KEY, 1, 2}}, 1, 0, nil, []slot{{KEY, 1, 1}, {}}},
+		{"no room for the used key", []slot{{KEY, 3, 2}, {HEALTH, 20, 1}}, 1, 0, errInventoryFull, []slot{{KEY, 3, 2}, {HEALTH, 20, 1}}},
+	}
+	for _, test := range tests {
+		inv := newTestInventory(test.slots...)
+		left, err := inv.spendCharges(0, test.charges)
+		if left != test.left || err != test.err {
+			t.Errorf("%s: got %v charges left and error %v, want %v and %v", test.name, left, err, test.left, test.err)
+		}
+		if got := getSlots(inv); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got slots %v, want %v", test.name, got, test.want)
+		}
+	}
+}
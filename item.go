@@ -11,6 +11,11 @@ const (
 	ARMOR          ItemType = iota // 1
 	HEALTH         ItemType = iota // 2
 	INSTANT_DAMAGE ItemType = iota // 3
+	BAG            ItemType = iota // 4 // NOTE: the effect for a bag is how many inventory slots it adds
+	WEAPON         ItemType = iota // 5 // NOTE: equipment, see getEquipStats for what the effect adds
+	HELMET         ItemType = iota // 6
+	RING           ItemType = iota // 7
+	AMULET         ItemType = iota // 8
 )
 
 type ItemType int8
@@ -19,17 +24,17 @@ type Item struct {
 	id     int64
 	iType  ItemType
 	effect float64
+	status *Status // put on whoever the item is used on, nil for none
+	count  int     // how many of the item are stacked in its slot, see Balance.StackSizes
+
+	rarity  Rarity // only equipment rolls one, see rollRarity
+	affixes []Affix
 }
 
 var itemIDCounter int64
 
-func getGenetateableItemsWithChance() (chances map[ItemType]float64) {
-	chances = make(map[ItemType]float64, INSTANT_DAMAGE+1)
-	chances[KEY] = 0.45 // THIS MUST REMAIN ABOVE OR EQUAL TO chestLockedChance SO ALL CHESTS CAN BE OPENED
-	chances[ARMOR] = 0.15
-	chances[HEALTH] = 0.25
-	chances[INSTANT_DAMAGE] = 0.15
-	return
+func getAllItemTypes() [9]ItemType {
+	return [9]ItemType{KEY, ARMOR, HEALTH, INSTANT_DAMAGE, BAG, WEAPON, HELMET, RING, AMULET}
 }
 
 func NewItem(iType ItemType, effect float64) *Item {
@@ -38,9 +43,47 @@ func NewItem(iType ItemType, effect float64) *Item {
 	itemIDCounter++
 	item.iType = iType
 	item.effect = effect
+	item.count = 1
 	return item
 }
 
+// split takes count items off the stack into a stack of their own.
+func (item *Item) split(count int) *Item {
+	split := NewItem(item.iType, item.effect)
+	if item.status != nil {
+		status := *item.status
+		split.status = &status
+	}
+	split.rarity = item.rarity
+	split.affixes = append([]Affix(nil), item.affixes...)
+	split.count = count
+	item.count -= count
+	return split
+}
+
+// stacksWith is true if the items are of the same type and tier, so they can
+// share a slot. Items with affixes are one of a kind.
+func (item *Item) stacksWith(other *Item) bool {
+	if item.iType != other.iType || item.effect != other.effect || item.rarity != other.rarity {
+		return false
+	}
+	if len(item.affixes) > 0 || len(other.affixes) > 0 {
+		return false
+	}
+	if item.status == nil || other.status == nil {
+		return item.status == other.status
+	}
+	return *item.status == *other.status
+}
+
+// getWeight is what the whole stack weighs, 0 for no item.
+func (item *Item) getWeight(balance *Balance) float64 {
+	if item == nil {
+		return 0
+	}
+	return balance.ItemWeights[getStringFromItemType(item.iType)] * float64(item.count)
+}
+
 func getStringFromItemType(iType ItemType) string {
 	switch iType {
 	case KEY:
@@ -51,77 +94,97 @@ func getStringFromItemType(iType ItemType) string {
 		return "Health"
 	case INSTANT_DAMAGE:
 		return "Damage" // TODO determine if name shoule be instant damage or just damage
+	case BAG:
+		return "Bag"
+	case WEAPON:
+		return "Weapon"
+	case HELMET:
+		return "Helmet"
+	case RING:
+		return "Ring"
+	case AMULET:
+		return "Amulet"
 	default:
 		return "INVALID"
 	}
 }
 
-func (item *Item) print() {
-	fmt.Printf("Item: Type=%-7s Effect=%7.3f\n", getStringFromItemType(item.iType), item.effect)
+func getItemTypeFromString(name string) ItemType {
+	for _, iType := range getAllItemTypes() {
+		if getStringFromItemType(iType) == name {
+			return iType
+		}
+	}
+	return -1
 }
 
-func createItemWithType(iType ItemType) *Item {
-	var effect float64
-	switch iType {
-	case KEY:
-		chanceNeeded := rand.Float64()
-		switch {
-		case .6 > chanceNeeded:
-			effect = 1
-		case .6+.3 > chanceNeeded:
-			effect = 2
-		case .6+.3+.1 > chanceNeeded:
-			effect = 3
-		default:
-			effect = 1
-		}
-	case ARMOR:
-		chanceNeeded := rand.Float64()
-		switch {
-		case .4 > chanceNeeded:
-			effect = 1
-		case .4+.3 > chanceNeeded:
-			effect = 2
-		case .4+.3+.2 > chanceNeeded:
-			effect = 3
-		case .4+.3+.2+.1 > chanceNeeded:
-			effect = 4
-		default:
-			effect = 1
-		}
-	case HEALTH:
-		chanceNeeded := rand.Float64()
-		switch {
-		case .5 > chanceNeeded:
-			effect = 20
-		case .5+.35 > chanceNeeded:
-			effect = 50
-		case .5+.35+.1 > chanceNeeded:
-			effect = 100
-		case .5+.35+.1+.05 > chanceNeeded:
-			effect = 200
-		default:
-			effect = 1
+// describe is the item for inventory listings, its name and rarity and what
+// it does.
+func (item *Item) describe(balance *Balance) string {
+	text := item.getName(balance)
+	if isEquipType(item.iType) {
+		text += " [" + getStringFromRarity(item.rarity) + "]"
+	}
+	return text + fmt.Sprintf("%s Type=%-7s Effect=%7.3f%s%s", item.describeCount(), getStringFromItemType(item.iType), item.effect, item.describeAffixes(), item.describeStatus())
+}
+
+// describeCount is the size of the stack for inventory listings, "" for a
+// single item.
+func (item *Item) describeCount() string {
+	if item.count <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" x%d", item.count)
+}
+
+// describeStatus is the status the item puts on for inventory listings, "" if
+// there is none.
+func (item *Item) describeStatus() string {
+	if item.status == nil {
+		return ""
+	}
+	return " Status=" + item.status.describe()
+}
+
+// getTierStatus is the status items of the tier with the given effect put on,
+// nil if the tier does not put one on.
+func getTierStatus(iType ItemType, effect float64, balance *Balance) *Status {
+	for _, tier := range balance.ItemTiers[getStringFromItemType(iType)] {
+		if tier.Effect == effect && tier.Status != "" {
+			return &Status{getStatusTypeFromString(tier.Status), tier.StatusPower, tier.StatusTurns}
 		}
-	case INSTANT_DAMAGE:
-		chanceNeeded := rand.Float64()
-		switch {
-		case .525 > chanceNeeded:
-			effect = 20
-		case .525+.375 > chanceNeeded:
-			effect = 50
-		case .525+.375+.075 > chanceNeeded:
-			effect = 100
-		case .525+.375+.075+.025 > chanceNeeded:
-			effect = 200
-		default:
-			effect = 1
+	}
+	return nil
+}
+
+// getTierName is the name of the items of the tier with the given effect, the
+// name of the item type if the tier has none.
+func getTierName(iType ItemType, effect float64, balance *Balance) string {
+	for _, tier := range balance.ItemTiers[getStringFromItemType(iType)] {
+		if tier.Effect == effect && tier.Name != "" {
+			return tier.Name
 		}
-	default:
+	}
+	return getStringFromItemType(iType)
+}
+
+// createItemWithType rolls the tier of a new item, better tiers are likelier
+// the deeper into the difficulty curve it is found.
+func createItemWithType(iType ItemType, depth float64, balance *Balance, rng *rand.Rand) *Item {
+	var effect float64
+	tiers := balance.ItemTiers[getStringFromItemType(iType)]
+	if len(tiers) == 0 {
 		fmt.Println("IMPOSSIBLE CASE: default case in generated item type", iType)
+	} else {
+		chances := make([]float64, len(tiers))
+		for i, tier := range tiers {
+			chances[i] = tier.Chance
+		}
+		effect = tiers[rollChance(rng, shiftChances(chances, balance.getDifficulty().TierShift, depth))].Effect
 	}
 
 	item := NewItem(iType, effect)
+	item.status = getTierStatus(iType, effect, balance)
 
 	return item
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Loot generation. Chests are filled from the loot table of their room type,
+// and equipment also rolls a rarity, from the RarityChances of the room type
+// shifted by how far out it is. The rarer it is the more affixes it gets, and
+// its name is built from the tier name and the affixes, e.g. "Sharp Sword of
+// the Brute".
+
+type Rarity int8
+
+const (
+	RARITY_COMMON    Rarity = iota
+	RARITY_UNCOMMON  Rarity = iota
+	RARITY_RARE      Rarity = iota
+	RARITY_EPIC      Rarity = iota
+	RARITY_LEGENDARY Rarity = iota
+)
+
+type rarityRule struct {
+	name    string
+	affixes int // affixes an item of the rarity rolls
+}
+
+var rarityRules = map[Rarity]rarityRule{
+	RARITY_COMMON:    {"Common", 0},
+	RARITY_UNCOMMON:  {"Uncommon", 1},
+	RARITY_RARE:      {"Rare", 2},
+	RARITY_EPIC:      {"Epic", 3},
+	RARITY_LEGENDARY: {"Legendary", 4},
+}
+
+func getAllRarities() [5]Rarity {
+	return [5]Rarity{RARITY_COMMON, RARITY_UNCOMMON, RARITY_RARE, RARITY_EPIC, RARITY_LEGENDARY}
+}
+
+func getStringFromRarity(rarity Rarity) string {
+	if rule, ok := rarityRules[rarity]; ok {
+		return rule.name
+	}
+	return "INVALID"
+}
+
+func getRarityFromString(name string) Rarity {
+	for _, rarity := range getAllRarities() {
+		if getStringFromRarity(rarity) == name {
+			return rarity
+		}
+	}
+	return -1
+}
+
+// The stats an affix can raise, see EquipStats.
+const (
+	STAT_DAMAGE     = "Damage"
+	STAT_DEFENSE    = "Defense"
+	STAT_STRENGTH   = "Strength"
+	STAT_MAX_HEALTH = "Max Health"
+	STAT_LIFESTEAL  = "Lifesteal" // share of the damage dealt healed
+)
+
+func getAllEquipStats() [5]string {
+	return [5]string{STAT_DAMAGE, STAT_DEFENSE, STAT_STRENGTH, STAT_MAX_HEALTH, STAT_LIFESTEAL}
+}
+
+func isEquipStat(stat string) bool {
+	for _, name := range getAllEquipStats() {
+		if name == stat {
+			return true
+		}
+	}
+	return false
+}
+
+// Affix is one rolled bonus of an item. Its name goes before the item name,
+// or after it for a suffix.
+type Affix struct {
+	name   string
+	suffix bool
+	stat   string
+	power  float64
+}
+
+func (a *Affix) describe() string {
+	if a.stat == STAT_LIFESTEAL {
+		return fmt.Sprintf("%.0f%% %s", a.power*100, a.stat)
+	}
+	return fmt.Sprintf("+%.2f %s", a.power, a.stat)
+}
+
+// getLootTable is the chance of each item type for a chest in a room of the
+// type, its LootTables entry or ItemChances if it has none.
+func getLootTable(rType RoomType, balance *Balance) map[ItemType]float64 {
+	table, ok := balance.LootTables[getPrintStringFromRoomType(rType)]
+	if !ok {
+		table = balance.ItemChances
+	}
+	chances := make(map[ItemType]float64, len(table))
+	for _, iType := range getAllItemTypes() {
+		chances[iType] = table[getStringFromItemType(iType)]
+	}
+	return chances
+}
+
+// rollLoot rolls an item for a chest in a room of the type, depth into the
+// difficulty curve.
+func rollLoot(rType RoomType, depth float64, balance *Balance, rng *rand.Rand) *Item {
+	table := getLootTable(rType, balance)
+	types := getAllItemTypes()
+	chances := make([]float64, len(types))
+	for i, iType := range types {
+		chances[i] = table[iType]
+	}
+	item := createItemWithType(types[rollChance(rng, chances)], depth, balance, rng)
+	if isEquipType(item.iType) {
+		item.rollRarity(rType, depth, balance, rng)
+	}
+	return item
+}
+
+// rollRarity rolls the rarity of the item and as many affixes as it calls
+// for, each one at most once.
+func (item *Item) rollRarity(rType RoomType, depth float64, balance *Balance, rng *rand.Rand) {
+	chances := balance.RarityChances[getPrintStringFromRoomType(rType)]
+	if len(chances) == 0 {
+		return
+	}
+	item.rarity = Rarity(rollChance(rng, shiftChances(chances, balance.getDifficulty().RarityShift, depth)))
+
+	var candidates []AffixStats
+	for _, affix := range balance.Affixes {
+		for _, name := range affix.Items {
+			if name == getStringFromItemType(item.iType) {
+				candidates = append(candidates, affix)
+				break
+			}
+		}
+	}
+	for i := 0; i < rarityRules[item.rarity].affixes && len(candidates) > 0; i++ {
+		pick := rng.Intn(len(candidates))
+		stats := candidates[pick]
+		candidates = append(candidates[:pick], candidates[pick+1:]...)
+		power := stats.Min + rng.Float64()*(stats.Max-stats.Min)
+		item.affixes = append(item.affixes, Affix{stats.Name, stats.Suffix, stats.Stat, power})
+	}
+}
+
+// getName is the name of the item, the name of its tier with its first prefix
+// and first suffix around it.
+func (item *Item) getName(balance *Balance) string {
+	name := getTierName(item.iType, item.effect, balance)
+	prefixed, suffixed := false, false
+	for _, affix := range item.affixes {
+		if affix.suffix && !suffixed {
+			name += " " + affix.name
+			suffixed = true
+		} else if !affix.suffix && !prefixed {
+			name = affix.name + " " + name
+			prefixed = true
+		}
+	}
+	return name
+}
+
+// describeAffixes lists the affixes of the item for inventory listings, "" if
+// there are none.
+func (item *Item) describeAffixes() string {
+	if len(item.affixes) == 0 {
+		return ""
+	}
+	bonuses := make([]string, len(item.affixes))
+	for i := range item.affixes {
+		bonuses[i] = item.affixes[i].describe()
+	}
+	return " (" + strings.Join(bonuses, ", ") + ")"
+}
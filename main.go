@@ -2,17 +2,26 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
 	"sort"
 	"strconv"
+	"time"
 )
 
 type Game struct {
-	rooms   [GameRaidus*2 + 1][GameRaidus*2 + 1]Room
+	radius  int64
+	rooms   [][]Room // indexed [y][x], radius*2+1 rooms on each side
+	start   Location // the Start Room, rings are counted out from it
 	chances map[RoomType]float64
 	moves   []*Move
+	player  *Player
+	balance *Balance
+	seed    int64
+	src     *countingSource
+	rng     *rand.Rand
 }
 
 // directions
@@ -25,129 +34,303 @@ const (
 
 type Direction int8
 
-const GameRaidus int64 = 30 // 30 tiles on each side
+func getStringFromDirection(dir Direction) string {
+	switch dir {
+	case UP:
+		return "UP"
+	case DOWN:
+		return "DOWN"
+	case LEFT:
+		return "LEFT"
+	case RIGHT:
+		return "RIGHT"
+	default:
+		return "INVALID"
+	}
+}
 
-// left included for legacy use
-const (
-	GameWidth  int64 = GameRaidus*2 + 1
-	GameHeight int64 = GameRaidus*2 + 1
-)
+func getDirectionFromString(name string) Direction {
+	for dir := UP; dir <= RIGHT; dir++ {
+		if getStringFromDirection(dir) == name {
+			return dir
+		}
+	}
+	return -1
+}
+
+// getOffsetFromDirection is how far one step in dir moves on the grid.
+func getOffsetFromDirection(dir Direction) Location {
+	switch dir {
+	case UP:
+		return Location{0, -1}
+	case DOWN:
+		return Location{0, 1}
+	case LEFT:
+		return Location{-1, 0}
+	case RIGHT:
+		return Location{1, 0}
+	default:
+		return Location{0, 0}
+	}
+}
+
+func getOppositeDirection(dir Direction) Direction {
+	switch dir {
+	case UP:
+		return DOWN
+	case DOWN:
+		return UP
+	case LEFT:
+		return RIGHT
+	case RIGHT:
+		return LEFT
+	default:
+		return -1
+	}
+}
+
+const DefaultGameRadius int64 = 30 // 30 tiles on each side
+
+func (game *Game) width() int64 {
+	return game.radius*2 + 1
+}
+
+func (game *Game) height() int64 {
+	return game.radius*2 + 1
+}
+
+// getNeighbour returns the room one step from (x, y) in dir, or nil if that is
+// off the grid.
+func (game *Game) getNeighbour(x, y int64, dir Direction) *Room {
+	offset := getOffsetFromDirection(dir)
+	x, y = x+offset.x, y+offset.y
+	if x < 0 || x >= game.width() || y < 0 || y >= game.height() {
+		return nil
+	}
+	return &game.rooms[y][x]
+}
+
+func newRoomGrid(radius int64) [][]Room {
+	rooms := make([][]Room, radius*2+1)
+	for y := range rooms {
+		rooms[y] = make([]Room, radius*2+1)
+	}
+	return rooms
+}
 
 var DEBUG_MODE = false
 
 const cheatInputNumber int8 = -111
-const HowSticky float64 = 0.25
 
 func main() {
-	args := os.Args[1:]
-	if len(args) > 0 {
-		debugStr := args[0]
-		debugBool, err := strconv.ParseBool(debugStr)
+	if len(os.Args) > 1 && os.Args[1] == "stats" {
+		runStats(os.Args[2:])
+		return
+	}
+
+	debug := flag.Bool("debug", false, "print world generation and engine debug output")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed for world generation and combat, the same seed gives the same game")
+	radius := flag.Int64("radius", DefaultGameRadius, "number of rooms on each side of the start room")
+	balancePath := flag.String("balance", "", "JSON balance file, anything left out keeps its default")
+	dumpBalance := flag.Bool("dump-balance", false, "print the default balance file and exit")
+	mapPath := flag.String("map", "", "play a text or .json map instead of generating a world")
+	exportPath := flag.String("export-map", "", "write the world to a text or .json map and exit")
+	difficulty := flag.String("difficulty", "", "difficulty preset of the balance to play on, asked for if left out")
+	flag.Parse()
+	if *dumpBalance {
+		defaultBalance().write(os.Stdout)
+		return
+	}
+	if *radius < 1 {
+		fmt.Println("The radius must be at least 1")
+		os.Exit(2)
+	}
+	DEBUG_MODE = *debug
+	// legacy: the debug flag used to be the first plain argument
+	if flag.NArg() > 0 {
+		debugBool, err := strconv.ParseBool(flag.Arg(0))
 		if err == nil {
 			DEBUG_MODE = debugBool
 		}
 	}
 
-	game := new(Game)
-	game.initRoomTypeChances()
-	game.initDefaultRoomType()
-	game.initRooms()
-	game.initRoomChests()
-	game.initEnemies()
-	game.initMoves()
+	balance := defaultBalance()
+	if *balancePath != "" {
+		var err error
+		balance, err = loadBalanceFromFile(*balancePath)
+		if err != nil {
+			fmt.Println("Could not load the balance file:", err)
+			os.Exit(1)
+		}
+	}
+
+	if *difficulty != "" {
+		if _, ok := balance.Difficulties[*difficulty]; !ok {
+			fmt.Println("Unknown difficulty", *difficulty)
+			os.Exit(2)
+		}
+		balance.Difficulty = *difficulty
+	} else if *exportPath == "" {
+		printDifficultyChoices(balance)
+	}
+
+	fmt.Println("Seed:", *seed)
+	var game *Game
+	if *mapPath != "" {
+		var err error
+		game, err = importMapFromFile(*mapPath, *seed, balance)
+		if err != nil {
+			fmt.Println("Could not load the map:", err)
+			os.Exit(1)
+		}
+	} else {
+		game = newGame(*seed, *radius, balance)
+	}
+	if *exportPath != "" {
+		if err := game.exportMapToFile(*exportPath); err != nil {
+			fmt.Println("Could not export the map:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Exported the map to", *exportPath)
+		return
+	}
 	game.calcStats()
 	if DEBUG_MODE {
 		printRooms(game)
 	}
 
-	var playerStartX = GameRaidus
-	var playerStartY = GameRaidus
-
-	plyr := newPlayer(&game.rooms[playerStartY][playerStartX], &Location{playerStartX, playerStartY}, game.moves[:3], game)
-
 	for {
-		run := plyr.update()
+		run := game.player.update()
 		if !run {
 			break
 		}
-		if plyr.movedLast {
-			plyr.currentRoom = &game.rooms[plyr.loc.y][plyr.loc.x]
-			if plyr.currentRoom.getNumEnemies() > 0 {
-				fmt.Println("\n\nYou have Encountered an Enemy!\nPrepare to Fight!")
-			}
-		}
 	}
 }
 
+// newGame generates a full world and player from the given seed. All
+// randomness in the game, generation and combat alike, comes from game.rng so
+// the same seed always plays out the same way for the same actions.
+func newGame(seed int64, radius int64, balance *Balance) *Game {
+	game := newBlankGame(seed, radius, balance)
+	game.start = Location{radius, radius}
+	game.initDefaultRoomType()
+	game.initRooms()
+	game.initRoomChests()
+	game.initEnemies()
+	game.connectRooms(game.start)
+	game.initMoves()
+	game.initPlayer(game.start.x, game.start.y)
+	return game
+}
+
+// newBlankGame sets up everything but the world and the player.
+func newBlankGame(seed int64, radius int64, balance *Balance) *Game {
+	game := new(Game)
+	game.balance = balance
+	game.seed = seed
+	game.radius = radius
+	game.rooms = newRoomGrid(radius)
+	game.src = newCountingSource(seed)
+	game.rng = rand.New(game.src)
+	game.initRoomTypeChances()
+	return game
+}
+
+func (game *Game) initPlayer(x, y int64) {
+	// the starting moves come first in game.moves, the rest have to be learned
+	game.player = newPlayer(&game.rooms[y][x], &Location{x, y}, game.moves[:len(game.balance.StartingMoves)], game)
+}
+
 func (game *Game) initRooms() {
-	roomID := int64(0)
 	// rng room generation spiraling out from the center
-	game.rooms[GameRaidus][GameRaidus].rType = START
-	for r := int64(1); r <= GameRaidus; r++ {
+	game.rooms[game.radius][game.radius].rType = START
+	for r := int64(1); r <= game.radius; r++ {
 		for t := int64(0); t < r*8; t++ {
-			var x int64
-			var y int64
-			if t < 2*r {
-				x = GameRaidus - r + t
-				y = GameRaidus - r
-			} else if t < 4*r {
-				x = GameRaidus + r
-				y = GameRaidus - (3 * r) + t
-			} else if t < 6*r {
-				x = GameRaidus + (5 * r) - t
-				y = GameRaidus + r
-			} else {
-				x = GameRaidus - r
-				y = GameRaidus + (7 * r) - t
-			}
+			x, y := game.getSpiralLocation(r, t)
 
 			game.rooms[y][x].rType = initRoomType(game, x, y)
 		}
 	}
 	// end room type loops
+	game.initBossRoom()
+	game.initMerchantRooms()
+
+	if DEBUG_MODE {
+		fmt.Println("=====================END TYPE=====================")
+		pause()
+		fmt.Println("=====================DOORS=====================")
+	}
+	game.initRoomDoors()
+}
+
+// countVisitedRooms is how many rooms the player has been in.
+func (game *Game) countVisitedRooms() int64 {
+	var count int64
+	for y := range game.rooms {
+		for x := range game.rooms[y] {
+			if game.rooms[y][x].visited {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// getSpiralLocation is room t of the 8*r rooms on ring r, going clockwise from
+// the top left corner of the ring.
+func (game *Game) getSpiralLocation(r, t int64) (x, y int64) {
+	if t < 2*r {
+		return game.radius - r + t, game.radius - r
+	} else if t < 4*r {
+		return game.radius + r, game.radius - (3 * r) + t
+	} else if t < 6*r {
+		return game.radius + (5 * r) - t, game.radius + r
+	}
+	return game.radius - r, game.radius + (7 * r) - t
+}
+
+// initBossRoom turns a random room on the outer ring into the Boss Room, the
+// goal of the game.
+func (game *Game) initBossRoom() {
+	x, y := game.getSpiralLocation(game.radius, game.rng.Int63n(game.radius*8))
+	game.rooms[y][x].rType = BOSS
+}
 
-	fmt.Println("=====================END TYPE=====================")
-	pause()
-	fmt.Println("=====================DOORS=====================")
-	// Init doors
-	// TODO dynamic doors
-	for y := int64(0); y < GameHeight; y++ {
-		for x := int64(0); x < GameWidth; x++ {
+// initRoomDoors numbers every room and rolls the door between each pair of
+// neighbouring rooms, either a wall, an open door or a locked door. Both rooms
+// get the same door. connectRooms makes sure the result can be explored.
+func (game *Game) initRoomDoors() {
+	roomID := int64(0)
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
 			current := &game.rooms[y][x]
 			current.loc = Location{x, y}
 			current.id = roomID
 			roomID++
 
-			up := Door{false, false}
-			down := Door{false, false}
-			left := Door{false, false}
-			right := Door{false, false}
-
-			if y == 0 {
-				down.exists = true
-			} else if y == GameHeight-1 {
-				up.exists = true
-			} else {
-				up.exists = true
-				down.exists = true
+			// the rooms up and left were done first and already rolled those doors
+			if x < game.width()-1 {
+				current.dRight = game.rollDoor()
+				game.rooms[y][x+1].dLeft = current.dRight
 			}
-
-			if x == 0 {
-				right.exists = true
-			} else if x == GameWidth-1 {
-				left.exists = true
-			} else {
-				left.exists = true
-				right.exists = true
+			if y < game.height()-1 {
+				current.dDown = game.rollDoor()
+				game.rooms[y+1][x].dUp = current.dDown
 			}
 			if DEBUG_MODE {
-				fmt.Println("Door init  ", x, y, up, down, left, right)
+				fmt.Println("Door init  ", x, y, current.dUp, current.dDown, current.dLeft, current.dRight)
 			}
-			current.initDoors(up, down, left, right)
 		}
 	}
 }
 
+func (game *Game) rollDoor() Door {
+	if game.rng.Float64() < game.balance.WallChance {
+		return Door{false, false}
+	}
+	return Door{true, game.rng.Float64() < game.balance.LockedDoorChance}
+}
+
 func initRoomType(game *Game, x int64, y int64) RoomType {
 	var adjecents [4]RoomType
 	if y > 0 {
@@ -155,12 +338,12 @@ func initRoomType(game *Game, x int64, y int64) RoomType {
 	} else {
 		adjecents[0] = -1
 	}
-	if y < GameRaidus*2 {
+	if y < game.radius*2 {
 		adjecents[1] = game.rooms[y+1][x].rType
 	} else {
 		adjecents[1] = -1
 	}
-	if x < GameRaidus*2 {
+	if x < game.radius*2 {
 		adjecents[2] = game.rooms[y][x+1].rType
 	} else {
 		adjecents[2] = -1
@@ -187,11 +370,11 @@ func initRoomType(game *Game, x int64, y int64) RoomType {
 			return HALLWAY
 		} else {
 			if val, ok := chances[adjecents[i]]; ok {
-				chances[adjecents[i]] = val + HowSticky
+				chances[adjecents[i]] = val + game.balance.HowSticky
 			} else {
-				chances[adjecents[i]] = HowSticky
+				chances[adjecents[i]] = game.balance.HowSticky
 			}
-			stickyLeft -= HowSticky
+			stickyLeft -= game.balance.HowSticky
 		}
 	}
 
@@ -216,7 +399,7 @@ func initRoomType(game *Game, x int64, y int64) RoomType {
 	})
 
 	chance := 0.0
-	chanceNeeded := rand.Float64()
+	chanceNeeded := game.rng.Float64()
 	if DEBUG_MODE {
 		defer func() {
 			fmt.Println("Location", Location{x, y})
@@ -241,58 +424,48 @@ func initRoomType(game *Game, x int64, y int64) RoomType {
 }
 
 func (game *Game) initRoomChests() {
-	for y := int64(0); y < GameHeight; y++ {
-		for x := int64(0); x < GameWidth; x++ {
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
 			current := &game.rooms[y][x]
-			current.initChests()
+			current.initChests(game.getRing(x, y), game.balance, game.rng)
+			current.initStock(game.getRing(x, y), game.balance, game.rng)
 		}
 	}
 }
 
+// initEnemies rolls the enemies of every room but the Start Room.
 func (game *Game) initEnemies() {
-	for r := int64(1); r <= GameRaidus; r++ {
-		for t := int64(0); t < r*8; t++ {
-			var x int64
-			var y int64
-			if t < 2*r {
-				x = GameRaidus - r + t
-				y = GameRaidus - r
-			} else if t < 4*r {
-				x = GameRaidus + r
-				y = GameRaidus - (3 * r) + t
-			} else if t < 6*r {
-				x = GameRaidus + (5 * r) - t
-				y = GameRaidus + r
-			} else {
-				x = GameRaidus - r
-				y = GameRaidus + (7 * r) - t
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
+			current := &game.rooms[y][x]
+			if current.rType == START {
+				continue
 			}
-
-			game.rooms[y][x].initEnemies(x, y, r)
+			current.initEnemies(x, y, game.getRing(x, y), game.balance, game.rng)
 		}
 	}
 }
 
 func (game *Game) initRoomTypeChances() {
 	game.chances = make(map[RoomType]float64, MYSTIC+1)
-	game.chances[START] = 0
-	game.chances[HALLWAY] = 0.5
-	game.chances[GREAT_HALL] = 0.2
-	game.chances[DUNGEON] = 0.15
-	game.chances[CHEST] = 0.1
-	game.chances[MYSTIC] = 0.05
+	for _, rType := range getGenetateableTypes() {
+		game.chances[rType] = game.balance.RoomChances[getPrintStringFromRoomType(rType)]
+	}
 }
 
 func (game *Game) initMoves() {
-	game.moves = make([]*Move, 3)
-	game.moves[0] = newMove(3.0, 6.0, "Punch", 0)
-	game.moves[1] = newMove(5.0, 10.0, "Kick", 0)
-	game.moves[2] = newMove(9.0, 15.0, "Special", 3)
+	game.moves = nil
+	for _, stats := range game.balance.StartingMoves {
+		game.moves = append(game.moves, newMoveFromStats(stats))
+	}
+	for _, stats := range game.balance.LearnableMoves {
+		game.moves = append(game.moves, newMoveFromStats(stats))
+	}
 }
 
 func (game *Game) initDefaultRoomType() {
-	for y := int64(0); y < GameHeight; y++ {
-		for x := int64(0); x < GameWidth; x++ {
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
 			current := &game.rooms[y][x]
 			current.rType = -1
 		}
@@ -301,8 +474,8 @@ func (game *Game) initDefaultRoomType() {
 
 func printRooms(game *Game) {
 	fmt.Println("- - - - - - - - - - - - - - - - - - - - - - - - - - -")
-	for y := int64(0); y < GameHeight; y++ {
-		for x := int64(0); x < GameWidth; x++ {
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
 			current := &game.rooms[y][x]
 			fmt.Printf("%T\n", current)
 			fmt.Println("id", current.id)
@@ -316,8 +489,8 @@ func printRooms(game *Game) {
 		}
 	}
 	fmt.Println("===============================================================")
-	for y := int64(0); y < GameHeight; y++ {
-		for x := int64(0); x < GameWidth; x++ {
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
 			current := &game.rooms[y][x]
 			fmt.Print(getPrintCharFromRoomType(current.rType))
 		}
@@ -326,176 +499,6 @@ func printRooms(game *Game) {
 	fmt.Println("===============================================================")
 }
 
-func (game *Game) calcStats() {
-	total := GameWidth * GameHeight
-	s, h, g, d, c, m := 0, 0, 0, 0, 0, 0
-	chests, lChests := 0, 0
-	rWch, rWe, rTot := 0, 0, 0
-	ep, en, eb, em, et := 0, 0, 0, 0, 0
-	// items, each new time on new line, each diff effective type new var
-	itemTotal := 0
-	k1, k2, k3, kT := 0, 0, 0, 0
-	a1, a2, a3, a4, aT := 0, 0, 0, 0, 0
-	h1, h2, h3, h4, hT := 0, 0, 0, 0, 0
-	d1, d2, d3, d4, dT := 0, 0, 0, 0, 0
-	for y := int64(0); y < GameHeight; y++ {
-		for x := int64(0); x < GameWidth; x++ {
-			current := &game.rooms[y][x]
-
-			rTot++
-			currentNumChests := current.getNumChests()
-			if currentNumChests > 0 {
-				rWch++
-				chests += currentNumChests
-				for _, chest := range current.chests {
-					if chest == nil {
-						continue
-					}
-					if chest.locked {
-						lChests++
-					}
-					item := chest.item
-					if item != nil {
-						itemTotal++
-						switch item.iType {
-						case KEY:
-							kT++
-							switch item.effect {
-							case 1:
-								k1++
-							case 2:
-								k2++
-							case 3:
-								k3++
-							default:
-							}
-						case ARMOR:
-							aT++
-							switch item.effect {
-							case 1:
-								a1++
-							case 2:
-								a2++
-							case 3:
-								a3++
-							case 4:
-								a4++
-							default:
-							}
-						case HEALTH:
-							hT++
-							switch item.effect {
-							case 20:
-								h1++
-							case 50:
-								h2++
-							case 100:
-								h3++
-							case 200:
-								h4++
-							default:
-							}
-						case INSTANT_DAMAGE:
-							dT++
-							switch item.effect {
-							case 20:
-								d1++
-							case 50:
-								d2++
-							case 100:
-								d3++
-							case 200:
-								d4++
-							default:
-							}
-						default:
-						}
-					}
-				}
-			}
-
-			if current.getNumEnemies() > 0 {
-				rWe++
-				et += current.getNumEnemies()
-				for _, val := range current.enemies {
-					if val == nil {
-						continue
-					}
-					switch val.eType {
-					case PEON:
-						ep++
-					case WARRIOR:
-						en++
-					case BRUTE:
-						eb++
-					case E_MYSTIC:
-						em++
-					}
-				}
-			}
-
-			switch current.rType {
-			case START:
-				s++
-			case HALLWAY:
-				h++
-			case GREAT_HALL:
-				g++
-			case DUNGEON:
-				d++
-			case CHEST:
-				c++
-			case MYSTIC:
-				m++
-			default:
-			}
-		}
-	}
-	fmt.Println("=====================Stats=====================")
-	fmt.Println("---------------------RTYPE---------------------")
-	fmt.Printf("START        %6d/%-7d = %9.6f%%\n", s, total, (float64(s) / float64(total) * 100.0))
-	fmt.Printf("HALLWAY      %6d/%-7d = %9.6f%%\n", h, total, (float64(h) / float64(total) * 100.0))
-	fmt.Printf("GREAT_HALL   %6d/%-7d = %9.6f%%\n", g, total, (float64(g) / float64(total) * 100.0))
-	fmt.Printf("DUNGEON      %6d/%-7d = %9.6f%%\n", d, total, (float64(d) / float64(total) * 100.0))
-	fmt.Printf("CHEST        %6d/%-7d = %9.6f%%\n", c, total, (float64(c) / float64(total) * 100.0))
-	fmt.Printf("MYSTIC       %6d/%-7d = %9.6f%%\n", m, total, (float64(m) / float64(total) * 100.0))
-	fmt.Println("---------------------ENEMY---------------------")
-	fmt.Printf("RWE/RTot     %6d/%-7d = %9.6f%%\n", rWe, rTot, (float64(rWe) / float64(rTot) * 100.0))
-	fmt.Printf("PEON         %6d/%-7d = %9.6f%%\n", ep, et, (float64(ep) / float64(et) * 100.0))
-	fmt.Printf("NORMAL       %6d/%-7d = %9.6f%%\n", en, et, (float64(en) / float64(et) * 100.0))
-	fmt.Printf("BRUTE        %6d/%-7d = %9.6f%%\n", eb, et, (float64(eb) / float64(et) * 100.0))
-	fmt.Printf("MYSTIC       %6d/%-7d = %9.6f%%\n", em, et, (float64(em) / float64(et) * 100.0))
-	fmt.Println("---------------------CHEST---------------------")
-	fmt.Printf("RWC/RTot     %6d/%-7d = %9.6f%%\n", rWch, rTot, (float64(rWch) / float64(rTot) * 100.0))
-	fmt.Printf("Locked/Total %6d/%-7d = %9.6f%%\n", lChests, chests, (float64(lChests) / float64(chests) * 100.0))
-	fmt.Println("---------------------ITEMS---------------------")
-	fmt.Printf("KEY          %6d/%-7d = %9.6f%%\n", kT, itemTotal, (float64(kT) / float64(itemTotal) * 100.0))
-	fmt.Printf("ARMOR        %6d/%-7d = %9.6f%%\n", aT, itemTotal, (float64(aT) / float64(itemTotal) * 100.0))
-	fmt.Printf("HEALTH       %6d/%-7d = %9.6f%%\n", hT, itemTotal, (float64(hT) / float64(itemTotal) * 100.0))
-	fmt.Printf("DAMAGE       %6d/%-7d = %9.6f%%\n", dT, itemTotal, (float64(dT) / float64(itemTotal) * 100.0))
-	fmt.Println("----------------------KEY----------------------")
-	fmt.Printf("KEY 1        %6d/%-7d = %9.6f%%\n", k1, kT, (float64(k1) / float64(kT) * 100.0))
-	fmt.Printf("KEY 2        %6d/%-7d = %9.6f%%\n", k2, kT, (float64(k2) / float64(kT) * 100.0))
-	fmt.Printf("KEY 3        %6d/%-7d = %9.6f%%\n", k3, kT, (float64(k3) / float64(kT) * 100.0))
-	fmt.Println("---------------------ARMOR---------------------")
-	fmt.Printf("ARMOR 1      %6d/%-7d = %9.6f%%\n", a1, aT, (float64(a1) / float64(aT) * 100.0))
-	fmt.Printf("ARMOR 2      %6d/%-7d = %9.6f%%\n", a2, aT, (float64(a2) / float64(aT) * 100.0))
-	fmt.Printf("ARMOR 3      %6d/%-7d = %9.6f%%\n", a3, aT, (float64(a3) / float64(aT) * 100.0))
-	fmt.Printf("ARMOR 4      %6d/%-7d = %9.6f%%\n", a4, aT, (float64(a4) / float64(aT) * 100.0))
-	fmt.Println("---------------------HEALTH--------------------")
-	fmt.Printf("HEALTH 1     %6d/%-7d = %9.6f%%\n", h1, hT, (float64(h1) / float64(hT) * 100.0))
-	fmt.Printf("HEALTH 2     %6d/%-7d = %9.6f%%\n", h2, hT, (float64(h2) / float64(hT) * 100.0))
-	fmt.Printf("HEALTH 3     %6d/%-7d = %9.6f%%\n", h3, hT, (float64(h3) / float64(hT) * 100.0))
-	fmt.Printf("HEALTH 4     %6d/%-7d = %9.6f%%\n", h4, hT, (float64(h4) / float64(hT) * 100.0))
-	fmt.Println("---------------------DAMAGE--------------------")
-	fmt.Printf("DAMAGE 1     %6d/%-7d = %9.6f%%\n", d1, dT, (float64(d1) / float64(dT) * 100.0))
-	fmt.Printf("DAMAGE 2     %6d/%-7d = %9.6f%%\n", d2, dT, (float64(d2) / float64(dT) * 100.0))
-	fmt.Printf("DAMAGE 3     %6d/%-7d = %9.6f%%\n", d3, dT, (float64(d3) / float64(dT) * 100.0))
-	fmt.Printf("DAMAGE 4     %6d/%-7d = %9.6f%%\n", d4, dT, (float64(d4) / float64(dT) * 100.0))
-	fmt.Println("======================END======================")
-	pause()
-}
-
 func pause() {
 	fmt.Print("Press 'Enter' to continue...")
 	bufio.NewReader(os.Stdin).ReadBytes('\n')
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// play performs the same fight on a game and returns every event message.
+func play(game *Game) []string {
+	clearRoom(game)
+	addEnemy(game, WARRIOR, 60)
+	var messages []string
+	for turn := 0; turn < 20 && game.player.state == Fighting; turn++ {
+		res := game.perform(attackAction(0, 0))
+		for _, event := range res.events {
+			messages = append(messages, event.message)
+		}
+	}
+	return messages
+}
+
+func TestSeededGamesAreDeterministic(t *testing.T) {
+	first := play(newTestGame(t))
+	second := play(newTestGame(t))
+	if len(first) == 0 {
+		t.Fatal("the fight had no events")
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("two games with the same seed played differently:\n%v\n%v", first, second)
+	}
+}
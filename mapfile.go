@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Maps are hand made or exported worlds. The text format is the room grid that
+// printRooms draws, one getPrintCharFromRoomType character per room, so the
+// old *RaidMap.txt files can be played. Doors, chests and enemies of a text
+// map are generated from the seed. The JSON format spells out every room.
+//
+// A map is a level, not a game in progress, enemies always start at full
+// health, merchants have a fresh stock and the player always starts in the
+// Start Room. Use a save file to keep a game in progress. A map without a Boss
+// Room cannot be won.
+
+// mapVersion is written into every JSON map, loading refuses newer versions.
+const mapVersion = 1
+
+type mapFile struct {
+	Version int
+	Radius  int64
+	Rooms   []mapRoom // rows first, the same order as a save file
+}
+
+type mapRoom struct {
+	X           int64
+	Y           int64
+	Type        string     // getPrintStringFromRoomType
+	Doors       []string   // getStringFromDirection of every side with a door
+	LockedDoors []string   `json:",omitempty"`
+	Chests      []mapChest `json:",omitempty"`
+	Enemies     []string   `json:",omitempty"` // getEnemyNameFromType
+}
+
+type mapChest struct {
+	Locked bool
+	Item   *mapItem // nil for an empty chest
+	Gold   int      `json:",omitempty"`
+}
+
+type mapItem struct {
+	Type    string // getStringFromItemType
+	Effect  float64
+	Rarity  string     `json:",omitempty"` // getStringFromRarity, Common if left out
+	Affixes []mapAffix `json:",omitempty"`
+}
+
+type mapAffix struct {
+	Name   string
+	Suffix bool   `json:",omitempty"`
+	Stat   string // one of getAllEquipStats
+	Power  float64
+}
+
+// isJSONMap picks the map format from the file extension.
+func isJSONMap(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+func (game *Game) writeTextMap(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
+			out.WriteString(getPrintCharFromRoomType(game.rooms[y][x].rType))
+		}
+		out.WriteString("\n")
+	}
+	return out.Flush()
+}
+
+func (game *Game) writeJSONMap(w io.Writer) error {
+	file := mapFile{Version: mapVersion, Radius: game.radius}
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
+			current := &game.rooms[y][x]
+			room := mapRoom{X: x, Y: y, Type: getPrintStringFromRoomType(current.rType), Doors: []string{}}
+			for dir := UP; dir <= RIGHT; dir++ {
+				door := current.getDoor(dir)
+				if door.exists {
+					room.Doors = append(room.Doors, getStringFromDirection(dir))
+				}
+				if door.exists && door.locked {
+					room.LockedDoors = append(room.LockedDoors, getStringFromDirection(dir))
+				}
+			}
+			for _, chest := range current.chests {
+				if chest == nil {
+					continue
+				}
+				saved := mapChest{Locked: chest.locked, Gold: chest.gold}
+				if chest.item != nil {
+					saved.Item = &mapItem{Type: getStringFromItemType(chest.item.iType), Effect: chest.item.effect}
+					if chest.item.rarity != RARITY_COMMON {
+						saved.Item.Rarity = getStringFromRarity(chest.item.rarity)
+					}
+					for _, affix := range chest.item.affixes {
+						saved.Item.Affixes = append(saved.Item.Affixes, mapAffix{affix.name, affix.suffix, affix.stat, affix.power})
+					}
+				}
+				room.Chests = append(room.Chests, saved)
+			}
+			for _, enemy := range current.enemies {
+				if enemy != nil {
+					room.Enemies = append(room.Enemies, getEnemyNameFromType(enemy.eType))
+				}
+			}
+			file.Rooms = append(file.Rooms, room)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(&file)
+}
+
+// exportMapToFile writes the world to path, as JSON if it ends in .json and
+// as a text map otherwise.
+func (game *Game) exportMapToFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if isJSONMap(path) {
+		err = game.writeJSONMap(f)
+	} else {
+		err = game.writeTextMap(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// readTextMap builds a game from the first grid in r. Blank lines before the
+// grid are skipped and the grid ends at the next blank line, so anything after
+// it (like the other worlds in a *RaidMap.txt file) is ignored.
+func readTextMap(r io.Reader, seed int64, balance *Balance) (*Game, error) {
+	var rows []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			if len(rows) > 0 {
+				break
+			}
+			continue
+		}
+		rows = append(rows, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	size := int64(len(rows))
+	if size < 3 || size%2 == 0 {
+		return nil, fmt.Errorf("map: the grid must have an odd number of rows, at least 3, got %d", size)
+	}
+	game := newBlankGame(seed, (size-1)/2, balance)
+	for y, row := range rows {
+		if int64(len(row)) != size {
+			return nil, fmt.Errorf("map: row %d is %d rooms wide, the map is %d rooms high", y+1, len(row), size)
+		}
+		for x, char := range row {
+			rType := getRoomTypeFromPrintChar(string(char))
+			if rType == -1 {
+				return nil, fmt.Errorf("map: unknown room %q at row %d column %d", char, y+1, x+1)
+			}
+			game.rooms[y][x].rType = rType
+		}
+	}
+	start, err := game.findStartRoom()
+	if err != nil {
+		return nil, err
+	}
+	game.start = start
+
+	game.initRoomDoors()
+	game.initRoomChests()
+	game.initEnemies()
+	game.connectRooms(start)
+	game.initMoves()
+	game.initPlayer(start.x, start.y)
+	return game, nil
+}
+
+// readJSONMap builds a game from a JSON map. Every room has to be listed once,
+// every door has to have a matching door on the other side and every room has
+// to be reachable from the start room without going through a locked door.
+func readJSONMap(r io.Reader, seed int64, balance *Balance) (*Game, error) {
+	var file mapFile
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+	if file.Version > mapVersion {
+		return nil, fmt.Errorf("map: version %d was written by a newer version of the game", file.Version)
+	}
+	size := file.Radius*2 + 1
+	if file.Radius < 1 || int64(len(file.Rooms)) != size*size {
+		return nil, fmt.Errorf("map: a radius of %d needs %d rooms, got %d", file.Radius, size*size, len(file.Rooms))
+	}
+
+	game := newBlankGame(seed, file.Radius, balance)
+	game.initDefaultRoomType()
+	for _, room := range file.Rooms {
+		if room.X < 0 || room.X >= size || room.Y < 0 || room.Y >= size {
+			return nil, fmt.Errorf("map: room %d,%d is outside of the map", room.X, room.Y)
+		}
+		current := &game.rooms[room.Y][room.X]
+		if current.rType != -1 {
+			return nil, fmt.Errorf("map: room %d,%d is listed twice", room.X, room.Y)
+		}
+		current.rType = getRoomTypeFromPrintString(room.Type)
+		if current.rType == -1 {
+			return nil, fmt.Errorf("map: unknown room type %q at %d,%d", room.Type, room.X, room.Y)
+		}
+		current.id = room.Y*size + room.X
+		current.loc = Location{room.X, room.Y}
+
+		for _, name := range room.Doors {
+			dir := getDirectionFromString(name)
+			if dir == -1 {
+				return nil, fmt.Errorf("map: unknown door %q at %d,%d", name, room.X, room.Y)
+			}
+			current.getDoor(dir).exists = true
+		}
+		for _, name := range room.LockedDoors {
+			dir := getDirectionFromString(name)
+			if dir == -1 || !current.getDoor(dir).exists {
+				return nil, fmt.Errorf("map: locked door %q at %d,%d is not a door", name, room.X, room.Y)
+			}
+			current.getDoor(dir).locked = true
+		}
+
+		for _, chest := range room.Chests {
+			if chest.Gold < 0 {
+				return nil, fmt.Errorf("map: a chest at %d,%d has negative gold", room.X, room.Y)
+			}
+			loaded := &Chest{locked: chest.Locked, gold: chest.Gold}
+			if chest.Item != nil {
+				iType := getItemTypeFromString(chest.Item.Type)
+				if iType == -1 {
+					return nil, fmt.Errorf("map: unknown item type %q at %d,%d", chest.Item.Type, room.X, room.Y)
+				}
+				loaded.item = NewItem(iType, chest.Item.Effect)
+				loaded.item.status = getTierStatus(iType, chest.Item.Effect, game.balance)
+				if chest.Item.Rarity != "" {
+					loaded.item.rarity = getRarityFromString(chest.Item.Rarity)
+					if loaded.item.rarity == -1 {
+						return nil, fmt.Errorf("map: unknown rarity %q at %d,%d", chest.Item.Rarity, room.X, room.Y)
+					}
+				}
+				for _, affix := range chest.Item.Affixes {
+					if !isEquipStat(affix.Stat) {
+						return nil, fmt.Errorf("map: unknown affix stat %q at %d,%d", affix.Stat, room.X, room.Y)
+					}
+					loaded.item.affixes = append(loaded.item.affixes, Affix{affix.Name, affix.Suffix, affix.Stat, affix.Power})
+				}
+			}
+			current.chests = append(current.chests, loaded)
+		}
+		for _, name := range room.Enemies {
+			eType := getEnemyTypeFromName(name)
+			if eType == -1 {
+				return nil, fmt.Errorf("map: unknown enemy type %q at %d,%d", name, room.X, room.Y)
+			}
+			current.enemies = append(current.enemies, NewEnemy(eType))
+		}
+	}
+
+	if err := game.checkDoors(); err != nil {
+		return nil, err
+	}
+	start, err := game.findStartRoom()
+	if err != nil {
+		return nil, err
+	}
+	game.start = start
+	// how tough the enemies are and what merchants sell depends on the ring,
+	// which is only known once the start room is found
+	difficulty := balance.getDifficulty()
+	for _, room := range file.Rooms {
+		current := &game.rooms[room.Y][room.X]
+		ring := game.getRing(room.X, room.Y)
+		for _, enemy := range current.enemies {
+			enemy.scale(difficulty, difficulty.getDepth(ring))
+		}
+		current.initStock(ring, balance, game.rng)
+	}
+	if reached := game.walkFromStart(start, false); reached != game.width()*game.height() {
+		return nil, fmt.Errorf("map: only %d of the %d rooms can be reached from the %s without a key", reached, game.width()*game.height(), getPrintStringFromRoomType(START))
+	}
+	game.initMoves()
+	game.initPlayer(start.x, start.y)
+	return game, nil
+}
+
+// checkDoors makes sure no door leads off the map and every door has a door
+// with the same lock on the other side.
+func (game *Game) checkDoors() error {
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
+			current := &game.rooms[y][x]
+			for dir := UP; dir <= RIGHT; dir++ {
+				door := current.getDoor(dir)
+				if !door.exists {
+					continue
+				}
+				neighbour := game.getNeighbour(x, y, dir)
+				if neighbour == nil {
+					return fmt.Errorf("map: the %s door of %d,%d leads off the map", getStringFromDirection(dir), x, y)
+				}
+				other := neighbour.getDoor(getOppositeDirection(dir))
+				if !other.exists || other.locked != door.locked {
+					return fmt.Errorf("map: the %s door of %d,%d does not match the %s door of %d,%d", getStringFromDirection(dir), x, y, getStringFromDirection(getOppositeDirection(dir)), neighbour.loc.x, neighbour.loc.y)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findStartRoom returns the location of the one Start Room on the map.
+func (game *Game) findStartRoom() (Location, error) {
+	var start Location
+	found := 0
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
+			if game.rooms[y][x].rType == START {
+				start = Location{x, y}
+				found++
+			}
+		}
+	}
+	if found != 1 {
+		return start, fmt.Errorf("map: there must be exactly one %s, found %d", getPrintStringFromRoomType(START), found)
+	}
+	return start, nil
+}
+
+// importMapFromFile builds a game from the map at path, read as JSON if it
+// ends in .json and as a text map otherwise.
+func importMapFromFile(path string, seed int64, balance *Balance) (*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if isJSONMap(path) {
+		return readJSONMap(f, seed, balance)
+	}
+	return readTextMap(f, seed, balance)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONMapRoundTrip(t *testing.T) {
+	game := newTestGame(t)
+	var first bytes.Buffer
+	if err := game.writeJSONMap(&first); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	loaded, err := readJSONMap(bytes.NewReader(first.Bytes()), testSeed, defaultBalance())
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if loaded.start != game.start {
+		t.Errorf("the loaded map starts at %+v, want %+v", loaded.start, game.start)
+	}
+	var second bytes.Buffer
+	if err := loaded.writeJSONMap(&second); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("the map changed on the way through a read and a write")
+	}
+}
+
+func TestJSONMapErrors(t *testing.T) {
+	var exported bytes.Buffer
+	if err := newTestGame(t).writeJSONMap(&exported); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// room returns the room at (x, y) of a map with the test radius
+	room := func(file *mapFile, x, y int64) *mapRoom {
+		return &file.Rooms[y*(testRadius*2+1)+x]
+	}
+
+	tests := []struct {
+		name   string
+		change func(file *mapFile)
+		want   string // part of the error
+	}{
+		{"door off the map", func(file *mapFile) {
+			room(file, 0, 0).Doors = append(room(file, 0, 0).Doors, getStringFromDirection(UP))
+		}, "leads off the map"},
+		{"one sided door", func(file *mapFile) {
+			room(file, 0, 0).Doors = []string{getStringFromDirection(RIGHT)}
+			room(file, 1, 0).Doors = []string{}
+			room(file, 1, 0).LockedDoors = nil
+		}, "does not match"},
+		{"one sided lock", func(file *mapFile) {
+			room(file, 0, 0).Doors = []string{getStringFromDirection(RIGHT)}
+			room(file, 0, 0).LockedDoors = []string{getStringFromDirection(RIGHT)}
+			room(file, 1, 0).Doors = []string{getStringFromDirection(LEFT)}
+			room(file, 1, 0).LockedDoors = nil
+		}, "does not match"},
+		{"no start room", func(file *mapFile) {
+			room(file, testRadius, testRadius).Type = getPrintStringFromRoomType(HALLWAY)
+		}, "there must be exactly one"},
+		{"walled in start room", func(file *mapFile) {
+			for dir := UP; dir <= RIGHT; dir++ {
+				offset := getOffsetFromDirection(dir)
+				neighbour := room(file, testRadius+offset.x, testRadius+offset.y)
+				neighbour.Doors = removeString(neighbour.Doors, getStringFromDirection(getOppositeDirection(dir)))
+				neighbour.LockedDoors = removeString(neighbour.LockedDoors, getStringFromDirection(getOppositeDirection(dir)))
+			}
+			room(file, testRadius, testRadius).Doors = []string{}
+			room(file, testRadius, testRadius).LockedDoors = nil
+		}, "can be reached"},
+		{"locked door only", func(file *mapFile) {
+			for dir := UP; dir <= RIGHT; dir++ {
+				offset := getOffsetFromDirection(dir)
+				neighbour := room(file, testRadius+offset.x, testRadius+offset.y)
+				opposite := getStringFromDirection(getOppositeDirection(dir))
+				neighbour.Doors = append(removeString(neighbour.Doors, opposite), opposite)
+				neighbour.LockedDoors = append(removeString(neighbour.LockedDoors, opposite), opposite)
+			}
+			all := []string{getStringFromDirection(UP), getStringFromDirection(DOWN), getStringFromDirection(LEFT), getStringFromDirection(RIGHT)}
+			room(file, testRadius, testRadius).Doors = all
+			room(file, testRadius, testRadius).LockedDoors = all
+		}, "can be reached"},
+	}
+	for _, test := range tests {
+		var file mapFile
+		if err := json.Unmarshal(exported.Bytes(), &file); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		test.change(&file)
+		changed, err := json.Marshal(&file)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		_, err = readJSONMap(bytes.NewReader(changed), testSeed, defaultBalance())
+		if err == nil {
+			t.Errorf("%s: the map loaded", test.name)
+		} else if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %q, want it to mention %q", test.name, err, test.want)
+		}
+	}
+}
+
+func removeString(list []string, s string) []string {
+	var kept []string
+	for _, item := range list {
+		if item != s {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func TestTextMapRingsStartAtTheStartRoom(t *testing.T) {
+	game, err := readTextMap(strings.NewReader("SHH\nHBH\nHHH\n"), testSeed, defaultBalance())
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if game.start != (Location{0, 0}) {
+		t.Fatalf("the map starts at %+v, want the top left corner", game.start)
+	}
+	rings := []struct {
+		x, y, ring int64
+	}{{0, 0, 0}, {1, 0, 1}, {1, 1, 1}, {2, 1, 2}, {2, 2, 2}}
+	for _, test := range rings {
+		if ring := game.getRing(test.x, test.y); ring != test.ring {
+			t.Errorf("%d,%d is on ring %d, want %d", test.x, test.y, ring, test.ring)
+		}
+	}
+	// the center of the map is not the start room, so it gets its enemies
+	boss := game.rooms[1][1].enemies
+	if len(boss) != 1 || boss[0].eType != WARLORD {
+		t.Errorf("the Boss Room in the center has enemies %v, want the warlord", boss)
+	}
+	if len(game.rooms[0][0].enemies) != 0 {
+		t.Error("the start room has enemies")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import "strings"
+
+// Fog of war. The player only knows the rooms they have been in and the rooms
+// they have seen through a door of one of those. drawMap draws what they know
+// around them, rooms as their getPrintCharFromRoomType character with the
+// doors between them.
+
+// MinimapRadius is how many rooms the map shows on each side of the player.
+const MinimapRadius = 5
+
+const (
+	mapPlayer      = '@'
+	mapEnemies     = '!' // a visited room with enemies left in it
+	mapChests      = '$' // a visited room with items or gold left in its chests
+	mapLockedDoor  = '#'
+	mapUnknownRoom = ' '
+)
+
+// visitRoom marks the room at (x, y) as visited and every room behind one of
+// its doors as seen.
+func (game *Game) visitRoom(x, y int64) {
+	current := &game.rooms[y][x]
+	current.visited = true
+	current.seen = true
+	for dir := UP; dir <= RIGHT; dir++ {
+		if neighbour := game.getNeighbour(x, y, dir); neighbour != nil && current.getDoor(dir).exists {
+			neighbour.seen = true
+		}
+	}
+}
+
+// hasLoot is true if any chest in the room, locked or not, still has an item
+// or gold.
+func (r *Room) hasLoot() bool {
+	return r.getNumChestsWithLoot() > 0
+}
+
+// getMapChar is how the room is drawn on the map. Seen rooms that have not
+// been visited are drawn in lower case, their contents are unknown.
+func (r *Room) getMapChar() byte {
+	switch {
+	case !r.seen:
+		return mapUnknownRoom
+	case !r.visited:
+		return strings.ToLower(getPrintCharFromRoomType(r.rType))[0]
+	case r.getNumEnemiesAlive() > 0:
+		return mapEnemies
+	case r.hasLoot():
+		return mapChests
+	default:
+		return getPrintCharFromRoomType(r.rType)[0]
+	}
+}
+
+// drawMap draws the known rooms up to radius rooms away from center, one line
+// per row of rooms and one for the doors below them. A door is drawn if the
+// room on either side of it has been visited.
+func (game *Game) drawMap(center Location, radius int64) []string {
+	minX, maxX := maxInt64(0, center.x-radius), minInt64(game.width()-1, center.x+radius)
+	minY, maxY := maxInt64(0, center.y-radius), minInt64(game.height()-1, center.y+radius)
+
+	var lines []string
+	for y := minY; y <= maxY; y++ {
+		rooms := make([]byte, 0, (maxX-minX)*2+1)
+		doors := make([]byte, 0, (maxX-minX)*2+1)
+		for x := minX; x <= maxX; x++ {
+			current := &game.rooms[y][x]
+			char := current.getMapChar()
+			if x == center.x && y == center.y {
+				char = mapPlayer
+			}
+			rooms = append(rooms, char)
+			doors = append(doors, game.getMapDoor(x, y, DOWN))
+			if x < maxX {
+				rooms = append(rooms, game.getMapDoor(x, y, RIGHT))
+				doors = append(doors, ' ')
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(rooms), " "))
+		if y < maxY {
+			lines = append(lines, strings.TrimRight(string(doors), " "))
+		}
+	}
+
+	// leave out the unknown rows above and below what is known
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// getMapDoor is how the door on the dir side of (x, y) is drawn, dir being
+// DOWN or RIGHT.
+func (game *Game) getMapDoor(x, y int64, dir Direction) byte {
+	neighbour := game.getNeighbour(x, y, dir)
+	if neighbour == nil || !(game.rooms[y][x].visited || neighbour.visited) {
+		return ' '
+	}
+	door := game.rooms[y][x].getDoor(dir)
+	switch {
+	case !door.exists:
+		return ' '
+	case door.locked:
+		return mapLockedDoor
+	case dir == RIGHT:
+		return '-'
+	default:
+		return '|'
+	}
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
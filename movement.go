@@ -0,0 +1,22 @@
+package main
+
+// getDestination is the room one step from from in dir, if the player can walk
+// there. Walking and running away both go through it, so the door, its lock and
+// the edge of the map are checked in one place.
+func (game *Game) getDestination(from Location, dir Direction) (*Room, error) {
+	if dir < UP || dir > RIGHT {
+		return nil, errBadDirection
+	}
+	current := &game.rooms[from.y][from.x]
+	if !current.canLeaveFrom(dir) {
+		if current.getDoor(dir).exists {
+			return nil, errDoorLocked
+		}
+		return nil, errNoDoor
+	}
+	dest := game.getNeighbour(from.x, from.y, dir)
+	if dest == nil {
+		return nil, errOffMap
+	}
+	return dest, nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// The move library. game.moves holds every move of the game, the starting
+// moves first and then the ones that can be learned at a Mystical Room shrine
+// or on level up. The player fights with a loadout of up to
+// Balance.LoadoutSize of the moves they know.
+
+type MoveEffect int8
+
+// Every effect but MOVE_HEAL puts a status on for effectTurns turns, see
+// getStatusFromMoveEffect. Harmful ones go on the targets that survive the
+// hit, the others on the player.
+const (
+	MOVE_NONE        MoveEffect = iota
+	MOVE_STUN        MoveEffect = iota // the target skips its next effectTurns turns
+	MOVE_BLEED       MoveEffect = iota // the target takes effectPower damage at the start of its next effectTurns turns
+	MOVE_ARMOR_BREAK MoveEffect = iota // the target loses effectPower defense for its next effectTurns turns
+	MOVE_HEAL        MoveEffect = iota // the player heals effectPower
+	MOVE_POISON      MoveEffect = iota // the target is poisoned, poison stacks
+	MOVE_WEAKEN      MoveEffect = iota // the target loses an effectPower share of its strength
+	MOVE_REGEN       MoveEffect = iota // the player heals effectPower at the end of their turns
+	MOVE_SHIELD      MoveEffect = iota // the player gains effectPower defense
+)
+
+func getAllMoveEffects() [9]MoveEffect {
+	return [9]MoveEffect{MOVE_NONE, MOVE_STUN, MOVE_BLEED, MOVE_ARMOR_BREAK, MOVE_HEAL, MOVE_POISON, MOVE_WEAKEN, MOVE_REGEN, MOVE_SHIELD}
+}
+
+func getStringFromMoveEffect(effect MoveEffect) string {
+	switch effect {
+	case MOVE_NONE:
+		return ""
+	case MOVE_STUN:
+		return "Stun"
+	case MOVE_BLEED:
+		return "Bleed"
+	case MOVE_ARMOR_BREAK:
+		return "Armor Break"
+	case MOVE_HEAL:
+		return "Heal"
+	case MOVE_POISON:
+		return "Poison"
+	case MOVE_WEAKEN:
+		return "Weaken"
+	case MOVE_REGEN:
+		return "Regen"
+	case MOVE_SHIELD:
+		return "Shield"
+	default:
+		return "INVALID"
+	}
+}
+
+func getMoveEffectFromString(name string) MoveEffect {
+	for _, effect := range getAllMoveEffects() {
+		if getStringFromMoveEffect(effect) == name {
+			return effect
+		}
+	}
+	return -1
+}
+
+// getStatusFromMoveEffect is the status a move effect puts on, -1 if it does
+// not put one on.
+func getStatusFromMoveEffect(effect MoveEffect) StatusType {
+	switch effect {
+	case MOVE_STUN:
+		return STATUS_STUN
+	case MOVE_BLEED:
+		return STATUS_BLEED
+	case MOVE_ARMOR_BREAK:
+		return STATUS_ARMOR_BROKEN
+	case MOVE_POISON:
+		return STATUS_POISON
+	case MOVE_WEAKEN:
+		return STATUS_WEAKENED
+	case MOVE_REGEN:
+		return STATUS_REGEN
+	case MOVE_SHIELD:
+		return STATUS_SHIELDED
+	default:
+		return -1
+	}
+}
+
+func newMoveFromStats(stats MoveStats) *Move {
+	m := newMove(stats.MinDamage, stats.MaxDamage, stats.Name, stats.Cooldown, stats.HitsAll)
+	if stats.Hits > 1 {
+		m.hits = stats.Hits
+	}
+	m.effect = getMoveEffectFromString(stats.Effect)
+	m.effectPower = stats.EffectPower
+	m.effectTurns = stats.EffectTurns
+	return m
+}
+
+// getDamage is the raw damage range of the move with the weapon damage added,
+// moves that do no damage stay that way.
+func (m *Move) getDamage(weapon float64) (min, max float64) {
+	if m.maxDamage <= 0 {
+		return m.minDamage, m.maxDamage
+	}
+	return m.minDamage + weapon, m.maxDamage + weapon
+}
+
+// needsTarget is false for moves that hit every enemy and moves that do no
+// damage at all.
+func (m *Move) needsTarget() bool {
+	return !m.hitsAll && m.maxDamage > 0
+}
+
+// describe is the damage and effects of the move, for menus. weapon is the raw
+// damage the player's weapon adds.
+func (m *Move) describe(strength, weapon float64) string {
+	min, max := m.getDamage(weapon)
+	text := fmt.Sprintf("%-15s %6.2f -%6.2f Damage", m.name, min*strength, max*strength)
+	if m.hits > 1 {
+		text += fmt.Sprintf(" (Hits %d times)", m.hits)
+	}
+	if m.hitsAll {
+		text += " (Hits all enemies)"
+	}
+	if m.effect == MOVE_HEAL {
+		text += fmt.Sprintf(" (Heals %.2f)", m.effectPower)
+	} else if sType := getStatusFromMoveEffect(m.effect); sType != -1 {
+		status := Status{sType, m.effectPower, m.effectTurns}
+		if isHarmful(sType) {
+			text += " (Inflicts " + status.describe() + ")"
+		} else {
+			text += " (Grants " + status.describe() + ")"
+		}
+	}
+	if m.maxCooldown > 0 {
+		text += fmt.Sprintf(" (Has Cooldown: %d Turns)", m.maxCooldown)
+	}
+	return text
+}
+
+// applyEffect puts the move's harmful status on an enemy it hit.
+func (m *Move) applyEffect(enemy *Enemy, res *Result) {
+	sType := getStatusFromMoveEffect(m.effect)
+	if sType != -1 && isHarmful(sType) {
+		enemy.statuses.add(sType, m.effectPower, m.effectTurns, enemy.getWho(), res)
+	}
+}
+
+// applySelfEffect heals the player or puts the move's helpful status on them.
+func (m *Move) applySelfEffect(p *Player, res *Result) {
+	if m.effect == MOVE_HEAL {
+		healed := p.healPlayer(m.effectPower)
+		res.addEvent(EventHealed, healed, "Your %s healed you for %.2f health.", m.name, healed)
+		return
+	}
+	sType := getStatusFromMoveEffect(m.effect)
+	if sType != -1 && !isHarmful(sType) {
+		p.statuses.add(sType, m.effectPower, m.effectTurns, "You", res)
+	}
+}
+
+func (p *Player) knowsMove(move *Move) bool {
+	for _, known := range p.knownMoves {
+		if known == move {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Player) hasEquippedMove(move *Move) bool {
+	for _, equipped := range p.moves {
+		if equipped == move {
+			return true
+		}
+	}
+	return false
+}
+
+// getSpareMoves returns the known moves that are not in the loadout.
+func (p *Player) getSpareMoves() []*Move {
+	var spare []*Move
+	for _, move := range p.knownMoves {
+		if !p.hasEquippedMove(move) {
+			spare = append(spare, move)
+		}
+	}
+	return spare
+}
+
+// learnMove teaches the player a random move they do not know yet and puts it
+// in the loadout if there is room. It returns false if there is nothing left
+// to learn.
+func (p *Player) learnMove(res *Result) bool {
+	var unknown []*Move
+	for _, move := range p.game.moves {
+		if !p.knowsMove(move) {
+			unknown = append(unknown, move)
+		}
+	}
+	if len(unknown) == 0 {
+		return false
+	}
+
+	move := unknown[p.game.rng.Intn(len(unknown))]
+	p.knownMoves = append(p.knownMoves, move)
+	if len(p.moves) < p.game.balance.LoadoutSize {
+		p.moves = append(p.moves, move)
+		res.addEvent(EventLearnedMove, 0, "You learned %s and added it to your loadout.", move.name)
+	} else {
+		res.addEvent(EventLearnedMove, 0, "You learned %s, swap it into your loadout from the moves menu.", move.name)
+	}
+	return true
+}
+
+// doSwapMove puts the known move at index known into loadout slot slot. The
+// slot right after the last move adds to the loadout if it is not full.
+func (p *Player) doSwapMove(slot, known int, res *Result) {
+	if p.state != Exploring {
+		res.err = errNotExploring
+		return
+	}
+	if known < 0 || known >= len(p.knownMoves) {
+		res.err = errUnknownMove
+		return
+	}
+	move := p.knownMoves[known]
+	if p.hasEquippedMove(move) {
+		res.err = errMoveInLoadout
+		return
+	}
+	if slot < 0 || slot > len(p.moves) || slot >= p.game.balance.LoadoutSize {
+		res.err = errInvalidMove
+		return
+	}
+
+	if slot == len(p.moves) {
+		p.moves = append(p.moves, move)
+		res.addEvent(EventSwappedMove, 0, "Added %s to your loadout.", move.name)
+	} else {
+		res.addEvent(EventSwappedMove, 0, "Swapped %s out for %s.", p.moves[slot].name, move.name)
+		p.moves[slot] = move
+	}
+	res.turnConsumed = true
+}
+
+// healPlayer gives the player up to amount health back and returns how much
+// they got.
+func (p *Player) healPlayer(amount float64) float64 {
+	healed := math.Max(0, math.Min(amount, p.getMaxHealth()-p.health))
+	p.health += healed
+	return healed
+}
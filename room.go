@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"math/rand"
-	"sort"
 )
 
 // room types
@@ -14,14 +13,8 @@ const (
 	DUNGEON    RoomType = iota
 	CHEST      RoomType = iota
 	MYSTIC     RoomType = iota
-)
-
-const chestLockedChance float64 = 0.4
-const (
-	noChest    float64 = 0.4
-	oneChest   float64 = 0.4
-	twoChest   float64 = 0.15
-	threeChest float64 = 0.05
+	BOSS       RoomType = iota // placed once on the outer ring, see initBossRoom
+	MERCHANT   RoomType = iota // placed on every Balance.MerchantRings rings, see initMerchantRooms
 )
 
 type Door struct {
@@ -32,6 +25,21 @@ type Door struct {
 type Chest struct {
 	locked bool
 	item   *Item
+	gold   int // looted along with the item
+}
+
+// describe is what the chest holds for menus, "Empty chest" if it holds
+// nothing.
+func (c *Chest) describe(balance *Balance) string {
+	switch {
+	case c.item != nil && c.gold > 0:
+		return fmt.Sprintf("%s and %d gold", c.item.describe(balance), c.gold)
+	case c.item != nil:
+		return c.item.describe(balance)
+	case c.gold > 0:
+		return fmt.Sprintf("%d gold", c.gold)
+	}
+	return "Empty chest"
 }
 
 type RoomType int8
@@ -46,259 +54,157 @@ type Room struct {
 	dDown   Door
 	dLeft   Door
 	dRight  Door
+
+	stock   []*Item // what the merchant of a Merchant room sells
+	buyBack []*Item // items sold to the merchant, the latest last
+
+	interacted bool // the room's interaction has been used, see roomInteractions
+	visited    bool // the player has been in the room
+	seen       bool // the player has been in the room or behind one of its doors
 }
 
 func getGenetateableTypes() [6]RoomType {
 	return [6]RoomType{START, HALLWAY, GREAT_HALL, DUNGEON, CHEST, MYSTIC}
 }
 
+func getAllRoomTypes() [8]RoomType {
+	return [8]RoomType{START, HALLWAY, GREAT_HALL, DUNGEON, CHEST, MYSTIC, BOSS, MERCHANT}
+}
+
 func (r *Room) canLeaveFrom(direction Direction) bool {
 	switch direction {
 	case UP:
-		return r.dUp.exists /* && !r.dUp.locked */
+		return r.dUp.exists && !r.dUp.locked
 	case DOWN:
-		return r.dDown.exists /* && !r.dDown.locked */
+		return r.dDown.exists && !r.dDown.locked
 	case LEFT:
-		return r.dLeft.exists /* && !r.dLeft.locked */
+		return r.dLeft.exists && !r.dLeft.locked
 	case RIGHT:
-		return r.dRight.exists /* && !r.dRight.locked */
+		return r.dRight.exists && !r.dRight.locked
 	default:
 		fmt.Println("D E F A U L T  C A S E ")
 		return false
 	}
 }
 
-func (r *Room) canRunFrom(chance float64) bool {
-	switch r.rType {
-	case START:
-		// should not have to call this but what ever
-		return true
-	case HALLWAY:
-		return chance < .7
-	case GREAT_HALL:
-		return chance < .6
-	case DUNGEON:
-		return chance < .2
-	case CHEST:
-		return chance < .4
-	case MYSTIC:
-		return chance < .3
+// getDoor returns the door on the given side of the room.
+func (r *Room) getDoor(direction Direction) *Door {
+	switch direction {
+	case UP:
+		return &r.dUp
+	case DOWN:
+		return &r.dDown
+	case LEFT:
+		return &r.dLeft
+	case RIGHT:
+		return &r.dRight
 	default:
-		fmt.Println("D E F A U L T  C A S E ")
-		return false
+		return nil
 	}
 }
 
-func (r *Room) canRunTo(chance float64) bool {
-	switch r.rType {
-	case START:
-		// should not have to call this but what ever
-		return true
-	case HALLWAY:
-		return chance < 1
-	case GREAT_HALL:
-		return chance < .9
-	case DUNGEON:
-		return chance < .3
-	case CHEST:
-		return chance < .7
-	case MYSTIC:
-		return chance < .6
-	default:
-		fmt.Println("D E F A U L T  C A S E ")
-		return false
-	}
+func (r *Room) canRunFrom(balance *Balance, chance float64) bool {
+	return chance < balance.RunFromChances[getPrintStringFromRoomType(r.rType)]
 }
 
-func (r *Room) initChests() {
-	r.chests = make([]*Chest, 3)
-	var numChests int
-	chanceNeeded := rand.Float64()
-	switch r.rType {
-	case START:
-		return // numChests is 0
-	case HALLWAY:
-		switch {
-		case .85 > chanceNeeded:
-			return // numChests is 0
-		case .85+.15 > chanceNeeded:
-			numChests = 1
-		}
-	case GREAT_HALL:
-		switch {
-		case .65 > chanceNeeded:
-			return // numChests is 0
-		case .65+.3 > chanceNeeded:
-			numChests = 1
-		case .65+.3+.05 > chanceNeeded:
-			numChests = 2
-		}
-	case DUNGEON:
-		switch {
-		case .5 > chanceNeeded:
-			return // numChests is 0
-		case .5+.3 > chanceNeeded:
-			numChests = 1
-		case .5+.3+.075 > chanceNeeded:
-			numChests = 2
-		}
-	case CHEST:
-		switch {
-		case .0 > chanceNeeded:
-			return // numChests is 0
-		case .0+.25 > chanceNeeded:
-			numChests = 1
-		case .0+.25+.5 > chanceNeeded:
-			numChests = 2
-		case .0+.25+.5+.25 > chanceNeeded:
-			numChests = 3
-		}
-	case MYSTIC:
-		switch {
-		case .05 > chanceNeeded:
-			return // numChests is 0
-		case .05+.35 > chanceNeeded:
-			numChests = 1
-		case .05+.35+.45 > chanceNeeded:
-			numChests = 2
-		case .05+.35+.45+.15 > chanceNeeded:
-			numChests = 3
-		}
+func (r *Room) canRunTo(balance *Balance, chance float64) bool {
+	return chance < balance.RunToChances[getPrintStringFromRoomType(r.rType)]
+}
+
+// initChests fills the room with chests, more of them and better items the
+// further out its ring is.
+func (r *Room) initChests(raid int64, balance *Balance, rng *rand.Rand) {
+	counts := balance.ChestCounts[getPrintStringFromRoomType(r.rType)]
+	if len(counts) == 0 {
+		return
 	}
+	difficulty := balance.getDifficulty()
+	depth := difficulty.getDepth(raid)
+	r.chests = make([]*Chest, len(counts)-1)
+	numChests := rollChance(rng, shiftChances(counts, difficulty.ChestShift, depth))
 
 	for i := 0; i < numChests; i++ {
 		chest := new(Chest)
-		chanceNeeded = rand.Float64()
-		if chestLockedChance > chanceNeeded {
+		chanceNeeded := rng.Float64()
+		if balance.ChestLockedChance > chanceNeeded {
 			chest.locked = true
 		}
 
-		itemChances := getGenetateableItemsWithChance()
-		var generatedType ItemType
-		chance := 0.0
-		chanceNeeded = rand.Float64()
-		keys := make([]ItemType, len(itemChances))
-		ind := 0
-		for key := range itemChances {
-			keys[ind] = key
-			ind++
-		}
-		sort.Slice(keys, func(i, j int) bool {
-			return keys[i] > keys[j]
-		})
-
-		for _, val := range keys {
-			chance += itemChances[val]
-			if chance > chanceNeeded {
-				generatedType = val
-				break
-			}
-		}
-
-		chest.item = createItemWithType(generatedType)
+		chest.item = rollLoot(r.rType, depth, balance, rng)
+		chest.gold = balance.ChestGold.roll(depth, rng)
 		r.chests[i] = chest
 	}
 }
 
-func (r *Room) initEnemies(x, y, raid int64) {
-	if r.rType == DUNGEON || r.rType == MYSTIC {
-		r.enemies = make([]*Enemy, 2)
-	} else {
-		r.enemies = make([]*Enemy, 1)
-	}
-	chanceNeeded := rand.Float64()
-
-	// NOTE: Any chance that is greater than one is assuming that the radius is large enough to
-	switch r.rType {
-	case START:
-		// impossible case but...
+// initEnemies rolls the enemies of the room, tougher ones the further out its
+// ring is.
+func (r *Room) initEnemies(x, y, raid int64, balance *Balance, rng *rand.Rand) {
+	difficulty := balance.getDifficulty()
+	depth := difficulty.getDepth(raid)
+	if r.rType == BOSS {
+		r.enemies = []*Enemy{NewEnemy(WARLORD)}
+		r.enemies[0].scale(difficulty, depth)
 		return
-	case HALLWAY:
-		switch {
-		case .7 > chanceNeeded:
-			return
-		case .7+.25 > chanceNeeded:
-			r.enemies[0] = NewEnemy(PEON)
-		case .7+.25+.05 > chanceNeeded:
-			r.enemies[0] = NewEnemy(WARRIOR)
-		}
-	case GREAT_HALL:
-		switch {
-		case .6 > chanceNeeded:
-			return
-		case .6+.1 > chanceNeeded:
-			r.enemies[0] = NewEnemy(PEON)
-		case .6+.1+.3 > chanceNeeded:
-			r.enemies[0] = NewEnemy(WARRIOR)
-		}
-	case DUNGEON:
-		switch {
-		case .025 > chanceNeeded:
-			r.enemies[0] = NewEnemy(PEON)
-		case .025+.025 > chanceNeeded:
-			r.enemies[0] = NewEnemy(PEON)
-			r.enemies[1] = NewEnemy(PEON)
-		case .025+.025+.05 > chanceNeeded:
-			r.enemies[0] = NewEnemy(WARRIOR)
-		case .025+.025+.05+.15 > chanceNeeded:
-			r.enemies[0] = NewEnemy(WARRIOR)
-			r.enemies[1] = NewEnemy(WARRIOR)
-		case .025+.025+.05+.15+.65 > chanceNeeded:
-			r.enemies[0] = NewEnemy(BRUTE)
-		case .025+.025+.05+.15+.65+.1 > chanceNeeded:
-			r.enemies[0] = NewEnemy(BRUTE)
-			r.enemies[1] = NewEnemy(BRUTE)
-		}
-	case CHEST:
-		switch {
-		case .4 > chanceNeeded:
-			return
-		case .4+.4 > chanceNeeded:
-			r.enemies[0] = NewEnemy(WARRIOR)
-		case .4+.4+.2 > chanceNeeded:
-			r.enemies[0] = NewEnemy(BRUTE)
-		}
-	case MYSTIC:
-		switch {
-		case .3 > chanceNeeded:
-			return
-		case .3+.2 > chanceNeeded:
-			r.enemies[0] = NewEnemy(WARRIOR)
-		case .3+.2+.25 > chanceNeeded:
-			r.enemies[0] = NewEnemy(E_MYSTIC)
-		case .3+.2+.25+.25 > chanceNeeded:
-			r.enemies[0] = NewEnemy(E_MYSTIC)
-			r.enemies[1] = NewEnemy(E_MYSTIC)
-		}
-	default:
-		// also impossible but...
+	}
+	spawns := balance.EnemySpawns[getPrintStringFromRoomType(r.rType)]
+	if len(spawns) == 0 {
 		return
-
 	}
-}
 
-func (r *Room) initDoors(up Door, do Door, le Door, ri Door) {
-	if DEBUG_MODE {
-		fmt.Println("init doors ", r.loc.x, r.loc.y, up, do, le, ri)
+	chances := make([]float64, len(spawns))
+	maxEnemies := 0
+	for i, spawn := range spawns {
+		chances[i] = spawn.Chance
+		if len(spawn.Enemies) > maxEnemies {
+			maxEnemies = len(spawn.Enemies)
+		}
 	}
-	r.dUp = Door{up.exists, up.locked}
-	r.dDown = Door{do.exists, do.locked}
-	r.dLeft = Door{le.exists, le.locked}
-	r.dRight = Door{ri.exists, ri.locked}
-	if DEBUG_MODE {
-		fmt.Println("post init doors", r.dUp, r.dDown, r.dLeft, r.dRight)
+	r.enemies = make([]*Enemy, maxEnemies)
+
+	spawn := spawns[rollChance(rng, shiftChances(chances, difficulty.SpawnShift, depth))]
+	for i, name := range spawn.Enemies {
+		r.enemies[i] = NewEnemy(getEnemyTypeFromName(name))
+		r.enemies[i].scale(difficulty, depth)
 	}
 }
 
 func (r *Room) getCurrentEnemy() *Enemy {
 	for _, enemy := range r.enemies {
-		if enemy != nil && enemy.health >= 0 {
+		if enemy.isAlive() {
 			return enemy
 		}
 	}
 	return nil
 }
 
+// getEnemy returns the living enemy at index of the room's enemies, or nil.
+func (r *Room) getEnemy(index int) *Enemy {
+	if index < 0 || index >= len(r.enemies) || !r.enemies[index].isAlive() {
+		return nil
+	}
+	return r.enemies[index]
+}
+
+// removeEnemy takes enemy out of the room, e.g. when it flees.
+func (r *Room) removeEnemy(enemy *Enemy) {
+	for i, current := range r.enemies {
+		if current == enemy {
+			r.enemies[i] = nil
+		}
+	}
+}
+
+// getLivingEnemySlots returns the indexes of every living enemy in the room.
+func (r *Room) getLivingEnemySlots() []int {
+	var slots []int
+	for i, enemy := range r.enemies {
+		if enemy.isAlive() {
+			slots = append(slots, i)
+		}
+	}
+	return slots
+}
+
 func (r *Room) getNumEnemies() int {
 	num := 0
 	for _, val := range r.enemies {
@@ -312,7 +218,7 @@ func (r *Room) getNumEnemies() int {
 func (r *Room) getNumEnemiesAlive() int {
 	num := 0
 	for _, val := range r.enemies {
-		if val != nil && val.health >= 0 {
+		if val.isAlive() {
 			num++
 		}
 	}
@@ -329,11 +235,13 @@ func (r *Room) getNumChests() int {
 	return numChests
 }
 
-func (r *Room) getNumChestsWithItem() int {
+// getNumChestsWithLoot counts the chests, locked or not, that still have an
+// item or gold in them.
+func (r *Room) getNumChestsWithLoot() int {
 	numChests := 0
 	for _, val := range r.chests {
 		if val != nil {
-			if val.item != nil {
+			if val.item != nil || val.gold > 0 {
 				numChests++
 			}
 		}
@@ -358,7 +266,7 @@ func (r *Room) getNumLootableChests() int {
 	for _, val := range r.chests {
 		if val != nil {
 			if !val.locked {
-				if val.item != nil {
+				if val.item != nil || val.gold > 0 {
 					numChests++
 				}
 			}
@@ -367,21 +275,15 @@ func (r *Room) getNumLootableChests() int {
 	return numChests
 }
 
-func (r *Room) unlockChests(amount int) {
-	if amount > r.getNumLockedChests() {
-		amount = r.getNumLockedChests()
-	}
-	for _, val := range r.chests {
-		if val != nil {
-			if val.locked {
-				val.locked = false
-				amount--
-			}
-			if amount == 0 {
-				break
-			}
+// getLockedChestSlots returns the indexes of every locked chest in the room.
+func (r *Room) getLockedChestSlots() []int {
+	var slots []int
+	for i, chest := range r.chests {
+		if chest != nil && chest.locked {
+			slots = append(slots, i)
 		}
 	}
+	return slots
 }
 
 func getPrintStringFromRoomType(rType RoomType) string {
@@ -398,11 +300,33 @@ func getPrintStringFromRoomType(rType RoomType) string {
 		return "Chest Room"
 	case MYSTIC:
 		return "Mystical Room"
+	case BOSS:
+		return "Boss Room"
+	case MERCHANT:
+		return "Merchant Room"
 	default:
 		return "_"
 	}
 }
 
+func getRoomTypeFromPrintString(name string) RoomType {
+	for _, rType := range getAllRoomTypes() {
+		if getPrintStringFromRoomType(rType) == name {
+			return rType
+		}
+	}
+	return -1
+}
+
+func getRoomTypeFromPrintChar(char string) RoomType {
+	for _, rType := range getAllRoomTypes() {
+		if getPrintCharFromRoomType(rType) == char {
+			return rType
+		}
+	}
+	return -1
+}
+
 func getPrintCharFromRoomType(rType RoomType) string {
 	switch rType {
 	case START:
@@ -417,6 +341,10 @@ func getPrintCharFromRoomType(rType RoomType) string {
 		return "C"
 	case MYSTIC:
 		return "M"
+	case BOSS:
+		return "B"
+	case MERCHANT:
+		return "T"
 	default:
 		return "_"
 	}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+)
+
+// saveVersion is written into every save file. Bump it whenever the layout of
+// saveFile changes, loading refuses files from a newer version.
+const saveVersion = 1
+
+const defaultSavePath = "fight.sav"
+
+var (
+	errSaveVersion = errors.New("save file was written by a newer version of the game")
+	errSaveCorrupt = errors.New("save file does not match the world it describes")
+)
+
+// countingSource wraps the seeded source and counts how many numbers have been
+// drawn from it. The count is saved so that a loaded game can reseed and skip
+// ahead to exactly where the saved game was.
+type countingSource struct {
+	src   rand.Source
+	draws uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+// The saved* types mirror the game structs with exported fields so they can be
+// written with encoding/json.
+
+type saveFile struct {
+	Version int
+	Seed    int64
+	Draws   uint64
+	Radius  int64
+	Balance *Balance
+	Moves   []savedMove
+	Rooms   []savedRoom
+	Player  savedPlayer
+}
+
+type savedMove struct {
+	ID          uint8
+	Name        string
+	MinDamage   float64
+	MaxDamage   float64
+	Cooldown    int32
+	MaxCooldown int32
+	HitsAll     bool
+	Hits        int
+	Effect      MoveEffect
+	EffectPower float64
+	EffectTurns int
+}
+
+type savedDoor struct {
+	Exists bool
+	Locked bool
+}
+
+type savedItem struct {
+	ID      int64
+	Type    ItemType
+	Effect  float64
+	Status  *savedStatus `json:",omitempty"`
+	Count   int          `json:",omitempty"` // 0 for a single item
+	Rarity  Rarity       `json:",omitempty"`
+	Affixes []savedAffix `json:",omitempty"`
+}
+
+type savedAffix struct {
+	Name   string
+	Suffix bool
+	Stat   string
+	Power  float64
+}
+
+type savedStatus struct {
+	Type  StatusType
+	Power float64
+	Turns int
+}
+
+type savedChest struct {
+	Locked bool
+	Item   *savedItem
+	Gold   int `json:",omitempty"`
+}
+
+type savedEnemy struct {
+	Type        EnemyType
+	Health      float64
+	MaxHealth   float64
+	Strength    float64
+	TurnCounter int
+	Statuses    []savedStatus `json:",omitempty"`
+}
+
+type savedRoom struct {
+	ID         int64
+	Type       RoomType
+	X          int64
+	Y          int64
+	Doors      [4]savedDoor // indexed by Direction
+	Chests     []*savedChest
+	Enemies    []*savedEnemy
+	Visited    bool
+	Seen       bool
+	Interacted bool
+	Stock      []*savedItem `json:",omitempty"`
+	BuyBack    []*savedItem `json:",omitempty"`
+}
+
+type savedPlayer struct {
+	State     PlayerState
+	X         int64
+	Y         int64
+	Health    float64
+	MaxHealth float64
+	Level     int
+	XP        float64
+	Defense   float64
+	Strength  float64
+	Moves     []uint8 // Move.id of each move in the loadout
+	Known     []uint8 // Move.id of each move the player knows
+	Items     []*savedItem
+	Statuses  []savedStatus
+	Kills     int
+	ItemsUsed int
+	Equipped  []*savedItem
+	Gold      int
+}
+
+func saveItem(item *Item) *savedItem {
+	if item == nil {
+		return nil
+	}
+	saved := &savedItem{ID: item.id, Type: item.iType, Effect: item.effect}
+	if item.count > 1 {
+		saved.Count = item.count
+	}
+	saved.Rarity = item.rarity
+	for _, affix := range item.affixes {
+		saved.Affixes = append(saved.Affixes, savedAffix{affix.name, affix.suffix, affix.stat, affix.power})
+	}
+	if item.status != nil {
+		saved.Status = &savedStatus{item.status.sType, item.status.power, item.status.turns}
+	}
+	return saved
+}
+
+func loadItem(saved *savedItem) *Item {
+	if saved == nil {
+		return nil
+	}
+	if saved.ID >= itemIDCounter {
+		itemIDCounter = saved.ID + 1
+	}
+	item := &Item{id: saved.ID, iType: saved.Type, effect: saved.Effect, count: maxInt(1, saved.Count), rarity: saved.Rarity}
+	for _, affix := range saved.Affixes {
+		item.affixes = append(item.affixes, Affix{affix.Name, affix.Suffix, affix.Stat, affix.Power})
+	}
+	if saved.Status != nil {
+		item.status = &Status{saved.Status.Type, saved.Status.Power, saved.Status.Turns}
+	}
+	return item
+}
+
+func saveStatuses(statuses Statuses) []savedStatus {
+	var saved []savedStatus
+	for _, status := range statuses {
+		saved = append(saved, savedStatus{status.sType, status.power, status.turns})
+	}
+	return saved
+}
+
+func loadStatuses(saved []savedStatus) Statuses {
+	var statuses Statuses
+	for _, status := range saved {
+		statuses = append(statuses, &Status{status.Type, status.Power, status.Turns})
+	}
+	return statuses
+}
+
+func (game *Game) save(w io.Writer) error {
+	file := saveFile{Version: saveVersion, Seed: game.seed, Radius: game.radius, Balance: game.balance}
+	if game.src != nil {
+		file.Draws = game.src.draws
+	}
+
+	for _, move := range game.moves {
+		file.Moves = append(file.Moves, savedMove{move.id, move.name, move.minDamage, move.maxDamage, move.cooldown, move.maxCooldown, move.hitsAll, move.hits, move.effect, move.effectPower, move.effectTurns})
+	}
+
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
+			current := &game.rooms[y][x]
+			room := savedRoom{ID: current.id, Type: current.rType, X: current.loc.x, Y: current.loc.y, Interacted: current.interacted, Visited: current.visited, Seen: current.seen}
+			room.Doors[UP] = savedDoor{current.dUp.exists, current.dUp.locked}
+			room.Doors[DOWN] = savedDoor{current.dDown.exists, current.dDown.locked}
+			room.Doors[LEFT] = savedDoor{current.dLeft.exists, current.dLeft.locked}
+			room.Doors[RIGHT] = savedDoor{current.dRight.exists, current.dRight.locked}
+			for _, chest := range current.chests {
+				if chest == nil {
+					room.Chests = append(room.Chests, nil)
+					continue
+				}
+				room.Chests = append(room.Chests, &savedChest{chest.locked, saveItem(chest.item), chest.gold})
+			}
+			for _, item := range current.stock {
+				room.Stock = append(room.Stock, saveItem(item))
+			}
+			for _, item := range current.buyBack {
+				room.BuyBack = append(room.BuyBack, saveItem(item))
+			}
+			for _, enemy := range current.enemies {
+				if enemy == nil {
+					room.Enemies = append(room.Enemies, nil)
+					continue
+				}
+				room.Enemies = append(room.Enemies, &savedEnemy{Type: enemy.eType, Health: enemy.health, MaxHealth: enemy.maxHealth, Strength: enemy.strength, TurnCounter: enemy.turnCounter, Statuses: saveStatuses(enemy.statuses)})
+			}
+			file.Rooms = append(file.Rooms, room)
+		}
+	}
+
+	p := game.player
+	file.Player = savedPlayer{
+		State:     p.state,
+		X:         p.loc.x,
+		Y:         p.loc.y,
+		Health:    p.health,
+		MaxHealth: p.maxHealth,
+		Level:     p.level,
+		XP:        p.xp,
+		Defense:   p.defense,
+		Strength:  p.strength,
+		Statuses:  saveStatuses(p.statuses),
+		Kills:     p.kills,
+		ItemsUsed: p.itemsUsed,
+		Gold:      p.gold,
+	}
+	for _, move := range p.moves {
+		file.Player.Moves = append(file.Player.Moves, move.id)
+	}
+	for _, move := range p.knownMoves {
+		file.Player.Known = append(file.Player.Known, move.id)
+	}
+	for _, item := range p.inventory.itemSlots {
+		file.Player.Items = append(file.Player.Items, saveItem(item))
+	}
+	for _, iType := range getEquipTypes() {
+		if item := p.inventory.getEquipped(iType); item != nil {
+			file.Player.Equipped = append(file.Player.Equipped, saveItem(item))
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(&file)
+}
+
+// load replaces the state of game with the saved game read from r. game is
+// left untouched if the file cannot be read or does not fit the world.
+func (game *Game) load(r io.Reader) error {
+	// balance settings added after a save was written keep their defaults
+	file := saveFile{Balance: defaultBalance()}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return err
+	}
+	if file.Version > saveVersion {
+		return errSaveVersion
+	}
+	size := file.Radius*2 + 1
+	if file.Radius < 1 || int64(len(file.Rooms)) != size*size || file.Balance == nil {
+		return errSaveCorrupt
+	}
+	if file.Player.X < 0 || file.Player.X >= size || file.Player.Y < 0 || file.Player.Y >= size {
+		return errSaveCorrupt
+	}
+
+	if err := file.Balance.validate(); err != nil {
+		return err
+	}
+
+	movesByID := make(map[uint8]*Move, len(file.Moves))
+	moves := make([]*Move, 0, len(file.Moves))
+	for _, saved := range file.Moves {
+		move := &Move{id: saved.ID, minDamage: saved.MinDamage, maxDamage: saved.MaxDamage, name: saved.Name, cooldown: saved.Cooldown, maxCooldown: saved.MaxCooldown, hitsAll: saved.HitsAll,
+			hits: saved.Hits, effect: saved.Effect, effectPower: saved.EffectPower, effectTurns: saved.EffectTurns}
+		movesByID[move.id] = move
+		moves = append(moves, move)
+	}
+	playerMoves := make([]*Move, 0, len(file.Player.Moves))
+	for _, id := range file.Player.Moves {
+		move, ok := movesByID[id]
+		if !ok {
+			return errSaveCorrupt
+		}
+		playerMoves = append(playerMoves, move)
+	}
+	knownMoves := make([]*Move, 0, len(file.Player.Known))
+	for _, id := range file.Player.Known {
+		move, ok := movesByID[id]
+		if !ok {
+			return errSaveCorrupt
+		}
+		knownMoves = append(knownMoves, move)
+	}
+
+	for _, item := range file.Player.Equipped {
+		if item == nil || !isEquipType(item.Type) {
+			return errSaveCorrupt
+		}
+	}
+	for _, room := range file.Rooms {
+		for _, item := range append(append([]*savedItem(nil), room.Stock...), room.BuyBack...) {
+			if item == nil {
+				return errSaveCorrupt
+			}
+		}
+	}
+
+	src := newCountingSource(file.Seed)
+	for src.draws < file.Draws {
+		src.Int63()
+	}
+	game.seed = file.Seed
+	game.src = src
+	game.rng = rand.New(src)
+	game.moves = moves
+	if file.Balance != nil {
+		game.balance = file.Balance
+	}
+	game.radius = file.Radius
+	game.rooms = newRoomGrid(file.Radius)
+	game.start = Location{file.Radius, file.Radius}
+
+	for i, saved := range file.Rooms {
+		current := &game.rooms[int64(i)/size][int64(i)%size]
+		current.id = saved.ID
+		current.rType = saved.Type
+		current.loc = Location{saved.X, saved.Y}
+		current.interacted = saved.Interacted
+		current.visited = saved.Visited
+		current.seen = saved.Seen
+		current.dUp = Door{saved.Doors[UP].Exists, saved.Doors[UP].Locked}
+		current.dDown = Door{saved.Doors[DOWN].Exists, saved.Doors[DOWN].Locked}
+		current.dLeft = Door{saved.Doors[LEFT].Exists, saved.Doors[LEFT].Locked}
+		current.dRight = Door{saved.Doors[RIGHT].Exists, saved.Doors[RIGHT].Locked}
+		current.chests = make([]*Chest, len(saved.Chests))
+		for j, chest := range saved.Chests {
+			if chest != nil {
+				current.chests[j] = &Chest{locked: chest.Locked, item: loadItem(chest.Item), gold: chest.Gold}
+			}
+		}
+		for _, item := range saved.Stock {
+			current.stock = append(current.stock, loadItem(item))
+		}
+		for _, item := range saved.BuyBack {
+			current.buyBack = append(current.buyBack, loadItem(item))
+		}
+		current.enemies = make([]*Enemy, len(saved.Enemies))
+		for j, enemy := range saved.Enemies {
+			if enemy != nil {
+				current.enemies[j] = &Enemy{eType: enemy.Type, health: enemy.Health, maxHealth: enemy.MaxHealth, strength: enemy.Strength, turnCounter: enemy.TurnCounter,
+					statuses: loadStatuses(enemy.Statuses)}
+			}
+		}
+	}
+
+	// the start room is not saved, a world without one has rings around the
+	// center like a generated one
+	if start, err := game.findStartRoom(); err == nil {
+		game.start = start
+	}
+
+	saved := file.Player
+	loc := &Location{saved.X, saved.Y}
+	p := newPlayer(&game.rooms[loc.y][loc.x], loc, playerMoves, game)
+	p.knownMoves = append([]*Move(nil), knownMoves...)
+	p.state = saved.State
+	p.health = saved.Health
+	p.maxHealth = saved.MaxHealth
+	p.level = saved.Level
+	p.xp = saved.XP
+	p.defense = saved.Defense
+	p.strength = saved.Strength
+	p.statuses = loadStatuses(saved.Statuses)
+	p.kills = saved.Kills
+	p.itemsUsed = saved.ItemsUsed
+	p.gold = saved.Gold
+	for _, item := range saved.Equipped {
+		p.inventory.equipped[item.Type] = loadItem(item)
+	}
+	// every slot is saved, so bags show in how many there are
+	if len(saved.Items) > p.inventory.size() {
+		p.inventory.itemSlots = make([]*Item, len(saved.Items))
+	}
+	for i, item := range saved.Items {
+		p.inventory.itemSlots[i] = loadItem(item)
+	}
+	game.player = p
+	return nil
+}
+
+func (game *Game) saveToFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = game.save(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (game *Game) loadFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return game.load(f)
+}
+
+// printSaveChoices asks for a file name and saves or loads the game.
+func (game *Game) printSaveChoices(load bool) {
+	var path string
+	fmt.Printf("Enter a file name (Enter for %s):\n", defaultSavePath)
+	_, err := fmt.Scanln(&path)
+	if err != nil || path == "" {
+		path = defaultSavePath
+	}
+
+	if load {
+		err = game.loadFromFile(path)
+	} else {
+		err = game.saveToFile(path)
+	}
+	if err != nil {
+		fmt.Println("An error occured:", err)
+		return
+	}
+	if load {
+		fmt.Println("Loaded game from", path)
+	} else {
+		fmt.Println("Saved game to", path)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoadContinuesTheRNG(t *testing.T) {
+	game := newTestGame(t)
+	// a few draws so the save has to skip ahead
+	game.rng.Float64()
+	game.rng.Float64()
+
+	var saved bytes.Buffer
+	if err := game.save(&saved); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded := newGame(testSeed+1, testRadius, defaultBalance())
+	if err := loaded.load(bytes.NewReader(saved.Bytes())); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.src.draws != game.src.draws {
+		t.Fatalf("loaded game is at draw %d, want %d", loaded.src.draws, game.src.draws)
+	}
+
+	want := play(game)
+	got := play(loaded)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("the loaded game played differently:\n%v\n%v", got, want)
+	}
+
+	var resaved bytes.Buffer
+	if err := loaded.save(&resaved); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	var again bytes.Buffer
+	if err := game.save(&again); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if !bytes.Equal(resaved.Bytes(), again.Bytes()) {
+		t.Error("the loaded game saves differently from the original after the same turns")
+	}
+}
+
+func TestLoadRefusesNewerSaves(t *testing.T) {
+	game := newTestGame(t)
+	save := []byte(`{"Version": 999, "Radius": 1}`)
+	if err := game.load(bytes.NewReader(save)); err != errSaveVersion {
+		t.Errorf("got error %v, want %v", err, errSaveVersion)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// The economy. Enemies drop gold and chests can hold some along with their
+// item. Merchant rooms sell a stock rolled from their loot table, priced by the
+// type, tier and rarity of each item, and pay Balance.SellShare of that for
+// anything sold to them. The last Balance.MerchantBuyBack items sold to a
+// merchant can be bought back for what they were sold for.
+
+// GoldRoll is a chance of finding gold, between Min and Max of it times
+// 1 + depth.
+type GoldRoll struct {
+	Chance float64
+	Min    int
+	Max    int
+}
+
+// roll returns the gold found depth into the difficulty curve, 0 if none is.
+func (g GoldRoll) roll(depth float64, rng *rand.Rand) int {
+	if rng.Float64() >= g.Chance {
+		return 0
+	}
+	gold := g.Min + rng.Intn(g.Max-g.Min+1)
+	return int(math.Round(float64(gold) * (1 + depth)))
+}
+
+// initMerchantRooms turns a random room on every Balance.MerchantRings-th ring
+// into a Merchant, the outer ring is left to the Boss Room.
+func (game *Game) initMerchantRooms() {
+	every := int64(game.balance.MerchantRings)
+	if every < 1 {
+		return
+	}
+	for r := every; r < game.radius; r += every {
+		x, y := game.getSpiralLocation(r, game.rng.Int63n(r*8))
+		game.rooms[y][x].rType = MERCHANT
+	}
+}
+
+// initStock fills the stock of a Merchant with Balance.MerchantStock items,
+// better ones the further out its ring is. Other rooms have nothing to sell.
+func (r *Room) initStock(ring int64, balance *Balance, rng *rand.Rand) {
+	if r.rType != MERCHANT {
+		return
+	}
+	depth := balance.getDifficulty().getDepth(ring)
+	r.stock = make([]*Item, balance.MerchantStock)
+	for i := range r.stock {
+		r.stock[i] = rollLoot(r.rType, depth, balance, rng)
+	}
+}
+
+// getTierIndex is the index of the tier with the given effect in the ItemTiers
+// of the type, 0 if none has it, e.g. for a key with charges used.
+func getTierIndex(iType ItemType, effect float64, balance *Balance) int {
+	for i, tier := range balance.ItemTiers[getStringFromItemType(iType)] {
+		if tier.Effect == effect {
+			return i
+		}
+	}
+	return 0
+}
+
+// getPrice is what a merchant asks for one of the item. Every tier above the
+// first adds Balance.TierPriceScale of the price of the type, and the rarity
+// multiplies it.
+func (item *Item) getPrice(balance *Balance) int {
+	price := balance.ItemPrices[getStringFromItemType(item.iType)]
+	price *= 1 + balance.TierPriceScale*float64(getTierIndex(item.iType, item.effect, balance))
+	if int(item.rarity) < len(balance.RarityPriceScales) {
+		price *= balance.RarityPriceScales[item.rarity]
+	}
+	return maxInt(1, int(math.Round(price)))
+}
+
+// getSellPrice is what a merchant pays for the whole stack.
+func (item *Item) getSellPrice(balance *Balance) int {
+	return int(math.Round(float64(item.getPrice(balance))*balance.SellShare)) * item.count
+}
+
+// getWares returns the stock of the merchant, or the items that can be bought
+// back from it.
+func (r *Room) getWares(buyBack bool) []*Item {
+	if buyBack {
+		return r.buyBack
+	}
+	return r.stock
+}
+
+// getWarePrice is what the item costs, buying back costs what it was sold for.
+func getWarePrice(item *Item, buyBack bool, balance *Balance) int {
+	if buyBack {
+		return item.getSellPrice(balance)
+	}
+	return item.getPrice(balance) * item.count
+}
+
+// doBuy buys the ware at index from the merchant of the room, from its stock
+// or its buy-back list.
+func (p *Player) doBuy(index int, buyBack bool, res *Result) {
+	room := p.currentRoom
+	if room.rType != MERCHANT {
+		res.err = errNoMerchant
+		return
+	}
+	wares := room.getWares(buyBack)
+	if index < 0 || index >= len(wares) {
+		res.err = errNoSuchWare
+		return
+	}
+	item := wares[index]
+	price := getWarePrice(item, buyBack, p.game.balance)
+	if price > p.gold {
+		res.err = errNotEnoughGold
+		return
+	}
+	if err := p.inventory.canAdd(item); err != nil {
+		res.err = err
+		return
+	}
+
+	wares = append(wares[:index], wares[index+1:]...)
+	if buyBack {
+		room.buyBack = wares
+	} else {
+		room.stock = wares
+	}
+	p.gold -= price
+	name := item.getName(p.game.balance) + item.describeCount()
+	p.inventory.place(item)
+	res.addEvent(EventBought, float64(price), "Bought %s for %d gold", name, price)
+	res.turnConsumed = true
+}
+
+// sell sells the stack at slot to the merchant of the room, who keeps it on
+// the buy-back list.
+func (p *Player) sell(slot int, res *Result) {
+	room := p.currentRoom
+	item := p.inventory.itemSlots[slot]
+	price := item.getSellPrice(p.game.balance)
+	p.inventory.itemSlots[slot] = nil
+	p.gold += price
+
+	limit := p.game.balance.MerchantBuyBack
+	if limit > 0 {
+		room.buyBack = append(room.buyBack, item)
+		if len(room.buyBack) > limit {
+			room.buyBack = room.buyBack[len(room.buyBack)-limit:]
+		}
+	}
+	res.addEvent(EventSold, float64(price), "Sold %s%s for %d gold", item.getName(p.game.balance), item.describeCount(), price)
+	res.turnConsumed = true
+}
+
+// gainGold adds gold found or dropped, if there is any.
+func (p *Player) gainGold(gold int, res *Result) {
+	if gold <= 0 {
+		return
+	}
+	p.gold += gold
+	res.addEvent(EventGainedGold, float64(gold), "You got %d gold.", gold)
+}
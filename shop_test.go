@@ -0,0 +1,134 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newShop turns the player's room into a merchant selling stock.
+func newShop(t *testing.T, stock ...*Item) (*Game, *Room) {
+	game := newTestGame(t)
+	here, _ := clearRoom(game)
+	here.rType = MERCHANT
+	here.stock = stock
+	here.buyBack = nil
+	return game, here
+}
+
+func TestBuy(t *testing.T) {
+	potion := NewItem(HEALTH, 20)
+	price := potion.getPrice(defaultBalance())
+
+	tests := []struct {
+		name  string
+		gold  int
+		full  bool // no room left in the inventory
+		ware  int
+		err   error
+		stock int // wares left after buying
+	}{
+		{"buy", price, false, 0, nil, 1},
+		{"no such ware", price, false, 2, errNoSuchWare, 2},
+		{"not enough gold", price - 1, false, 0, errNotEnoughGold, 2},
+		{"no room", price, true, 0, errInventoryFull, 2},
+	}
+	for _, test := range tests {
+		game, here := newShop(t, NewItem(HEALTH, 20), NewItem(KEY, 1))
+		p := game.player
+		p.gold = test.gold
+		if test.full {
+			for i := range p.inventory.itemSlots {
+				p.inventory.itemSlots[i] = NewItem(ARMOR, 1)
+			}
+		}
+
+		res := game.perform(buyAction(test.ware))
+		if res.err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, res.err, test.err)
+		}
+		if len(here.stock) != test.stock {
+			t.Errorf("%s: the merchant has %d wares left, want %d", test.name, len(here.stock), test.stock)
+		}
+		if test.err != nil {
+			if p.gold != test.gold || res.turnConsumed {
+				t.Errorf("%s: a failed buy took gold or the turn", test.name)
+			}
+			continue
+		}
+		if p.gold != test.gold-price || p.inventory.itemSlots[0] == nil || p.inventory.itemSlots[0].iType != HEALTH {
+			t.Errorf("%s: got %d gold and slot %v, want %d gold and the potion", test.name, p.gold, p.inventory.itemSlots[0], test.gold-price)
+		}
+		if !res.turnConsumed || !hasEvent(res, EventBought) {
+			t.Errorf("%s: got events %v, want EventBought on a consumed turn", test.name, eventTypes(res))
+		}
+	}
+}
+
+func TestSellAndBuyBack(t *testing.T) {
+	game, here := newShop(t)
+	p := game.player
+	game.balance.MerchantBuyBack = 1
+	first := NewItem(KEY, 1)
+	second := NewItem(HEALTH, 20)
+	second.count = 2
+	p.inventory.itemSlots[0] = first
+	p.inventory.itemSlots[1] = second
+	firstPrice := first.getSellPrice(game.balance)
+	secondPrice := second.getSellPrice(game.balance)
+
+	for _, slot := range []int{0, 1} {
+		res := game.perform(discardAction(slot))
+		if res.err != nil || !hasEvent(res, EventSold) {
+			t.Fatalf("sell slot %d: got error %v and events %v", slot, res.err, eventTypes(res))
+		}
+	}
+	if p.gold != firstPrice+secondPrice || p.inventory.slotsUsed() != 0 {
+		t.Errorf("got %d gold and %d items, want %d gold and none", p.gold, p.inventory.slotsUsed(), firstPrice+secondPrice)
+	}
+	// only the last item sold can be bought back
+	if !reflect.DeepEqual(here.buyBack, []*Item{second}) {
+		t.Fatalf("the merchant can sell back %v, want only the last item sold", here.buyBack)
+	}
+
+	res := game.perform(buyBackAction(0))
+	if res.err != nil {
+		t.Fatalf("buy back: %v", res.err)
+	}
+	if p.gold != firstPrice || p.inventory.itemSlots[0] != second || second.count != 2 {
+		t.Errorf("buying back cost %d gold, want the %d it was sold for", firstPrice+secondPrice-p.gold, secondPrice)
+	}
+	if len(here.buyBack) != 0 {
+		t.Error("the item bought back is still on the buy-back list")
+	}
+}
+
+func TestSellOutsideAShop(t *testing.T) {
+	game := newTestGame(t)
+	clearRoom(game)
+	p := game.player
+	p.inventory.itemSlots[0] = NewItem(KEY, 1)
+
+	res := game.perform(discardAction(0))
+	if res.err != nil || !hasEvent(res, EventDiscarded) || p.gold != 0 {
+		t.Errorf("got error %v, events %v and %d gold, want the item thrown away for nothing", res.err, eventTypes(res), p.gold)
+	}
+}
+
+func TestChestDescribe(t *testing.T) {
+	balance := defaultBalance()
+	key := NewItem(KEY, 1)
+	tests := []struct {
+		chest Chest
+		want  string
+	}{
+		{Chest{item: key, gold: 7}, key.describe(balance) + " and 7 gold"},
+		{Chest{item: key}, key.describe(balance)},
+		{Chest{gold: 7}, "7 gold"},
+		{Chest{}, "Empty chest"},
+	}
+	for _, test := range tests {
+		if got := test.chest.describe(balance); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
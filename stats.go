@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// stat is one entry of a world's distributions, Count out of Total.
+type stat struct {
+	Category string
+	Name     string
+	Count    int
+	Total    int
+}
+
+func (s stat) percent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Count) / float64(s.Total) * 100.0
+}
+
+// statSummary is one stat aggregated over many worlds.
+type statSummary struct {
+	Category        string
+	Name            string
+	MeanCount       float64
+	VarianceCount   float64
+	MeanPercent     float64
+	VariancePercent float64
+}
+
+type statsReport struct {
+	Worlds    int
+	Radius    int64
+	FirstSeed int64
+	Stats     []statSummary
+}
+
+// collectStats counts the room, enemy, chest and item distributions of the world.
+func (game *Game) collectStats() []stat {
+	rooms := make(map[RoomType]int)
+	enemies := make(map[EnemyType]int)
+	items := make(map[ItemType]int)
+	tiers := make(map[ItemType]map[float64]int)
+	rarities := make(map[Rarity]int)
+	equipment := 0
+	roomsTotal, roomsWithEnemies, enemiesTotal := 0, 0, 0
+	roomsWithChests, chests, lockedChests, goldChests, itemsTotal := 0, 0, 0, 0, 0
+	sides, walls, doors, lockedDoors := 0, 0, 0, 0
+
+	for y := int64(0); y < game.height(); y++ {
+		for x := int64(0); x < game.width(); x++ {
+			current := &game.rooms[y][x]
+			roomsTotal++
+			rooms[current.rType]++
+
+			// every door is shared by two rooms, only count the right and down ones
+			for _, dir := range []Direction{DOWN, RIGHT} {
+				if game.getNeighbour(x, y, dir) == nil {
+					continue
+				}
+				sides++
+				door := current.getDoor(dir)
+				if !door.exists {
+					walls++
+					continue
+				}
+				doors++
+				if door.locked {
+					lockedDoors++
+				}
+			}
+
+			if current.getNumChests() > 0 {
+				roomsWithChests++
+				for _, chest := range current.chests {
+					if chest == nil {
+						continue
+					}
+					chests++
+					if chest.locked {
+						lockedChests++
+					}
+					if chest.gold > 0 {
+						goldChests++
+					}
+					item := chest.item
+					if item != nil {
+						itemsTotal++
+						items[item.iType]++
+						if tiers[item.iType] == nil {
+							tiers[item.iType] = make(map[float64]int)
+						}
+						tiers[item.iType][item.effect]++
+						if isEquipType(item.iType) {
+							equipment++
+							rarities[item.rarity]++
+						}
+					}
+				}
+			}
+
+			if current.getNumEnemies() > 0 {
+				roomsWithEnemies++
+				for _, enemy := range current.enemies {
+					if enemy != nil {
+						enemiesTotal++
+						enemies[enemy.eType]++
+					}
+				}
+			}
+		}
+	}
+
+	var stats []stat
+	for _, rType := range getAllRoomTypes() {
+		stats = append(stats, stat{"Room", getPrintStringFromRoomType(rType), rooms[rType], roomsTotal})
+	}
+	stats = append(stats, stat{"Door", "Walls", walls, sides})
+	stats = append(stats, stat{"Door", "Locked", lockedDoors, doors})
+	stats = append(stats, stat{"Enemy", "With enemies", roomsWithEnemies, roomsTotal})
+	for _, eType := range getAllEnemyTypes() {
+		stats = append(stats, stat{"Enemy", getEnemyNameFromType(eType), enemies[eType], enemiesTotal})
+	}
+	stats = append(stats, stat{"Chest", "With chests", roomsWithChests, roomsTotal})
+	stats = append(stats, stat{"Chest", "Locked", lockedChests, chests})
+	stats = append(stats, stat{"Chest", "With gold", goldChests, chests})
+	for _, iType := range getAllItemTypes() {
+		stats = append(stats, stat{"Item", getStringFromItemType(iType), items[iType], itemsTotal})
+	}
+	for _, iType := range getAllItemTypes() {
+		name := getStringFromItemType(iType)
+		// walk the tiers in balance order so every world lists the same entries
+		for _, tier := range game.balance.ItemTiers[name] {
+			stats = append(stats, stat{name, fmt.Sprintf("%s %g", name, tier.Effect), tiers[iType][tier.Effect], items[iType]})
+		}
+	}
+	for _, rarity := range getAllRarities() {
+		stats = append(stats, stat{"Rarity", getStringFromRarity(rarity), rarities[rarity], equipment})
+	}
+	return stats
+}
+
+// summarizeStats works out the mean and sample variance of every stat over
+// all the worlds, keeping the order the stats were first seen in.
+func summarizeStats(worlds [][]stat) []statSummary {
+	var order []string
+	samples := make(map[string][]stat)
+	for _, world := range worlds {
+		for _, s := range world {
+			key := s.Category + "/" + s.Name
+			if _, ok := samples[key]; !ok {
+				order = append(order, key)
+			}
+			samples[key] = append(samples[key], s)
+		}
+	}
+
+	summaries := make([]statSummary, 0, len(order))
+	for _, key := range order {
+		list := samples[key]
+		n := float64(len(list))
+		summary := statSummary{Category: list[0].Category, Name: list[0].Name}
+		for _, s := range list {
+			summary.MeanCount += float64(s.Count)
+			summary.MeanPercent += s.percent()
+		}
+		summary.MeanCount /= n
+		summary.MeanPercent /= n
+		if len(list) > 1 {
+			for _, s := range list {
+				dc := float64(s.Count) - summary.MeanCount
+				dp := s.percent() - summary.MeanPercent
+				summary.VarianceCount += dc * dc
+				summary.VariancePercent += dp * dp
+			}
+			summary.VarianceCount /= n - 1
+			summary.VariancePercent /= n - 1
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func (report *statsReport) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(report)
+}
+
+func (report *statsReport) writeCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"category", "name", "mean_count", "variance_count", "mean_percent", "variance_percent"})
+	for _, s := range report.Stats {
+		out.Write([]string{
+			s.Category,
+			s.Name,
+			strconv.FormatFloat(s.MeanCount, 'f', -1, 64),
+			strconv.FormatFloat(s.VarianceCount, 'f', -1, 64),
+			strconv.FormatFloat(s.MeanPercent, 'f', -1, 64),
+			strconv.FormatFloat(s.VariancePercent, 'f', -1, 64),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// runStats is the stats subcommand. It generates worlds for a range of seeds
+// without any input and writes the aggregated distributions.
+func runStats(args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	worlds := flags.Int("worlds", 10, "number of worlds to generate")
+	radius := flags.Int64("radius", DefaultGameRadius, "number of rooms on each side of the start room")
+	seed := flags.Int64("seed", 0, "seed of the first world, world i uses seed+i")
+	format := flags.String("format", "json", "output format, json or csv")
+	balancePath := flags.String("balance", "", "JSON balance file, anything left out keeps its default")
+	difficulty := flags.String("difficulty", "", "difficulty preset of the balance, its Difficulty if left out")
+	flags.Parse(args)
+
+	if *worlds < 1 || *radius < 1 {
+		fmt.Fprintln(os.Stderr, "worlds and radius must be at least 1")
+		os.Exit(2)
+	}
+	balance := defaultBalance()
+	if *balancePath != "" {
+		var err error
+		balance, err = loadBalanceFromFile(*balancePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not load the balance file:", err)
+			os.Exit(1)
+		}
+	}
+
+	if *difficulty != "" {
+		if _, ok := balance.Difficulties[*difficulty]; !ok {
+			fmt.Fprintln(os.Stderr, "unknown difficulty", *difficulty)
+			os.Exit(2)
+		}
+		balance.Difficulty = *difficulty
+	}
+
+	all := make([][]stat, *worlds)
+	for i := range all {
+		all[i] = newGame(*seed+int64(i), *radius, balance).collectStats()
+	}
+	report := &statsReport{*worlds, *radius, *seed, summarizeStats(all)}
+
+	var err error
+	switch strings.ToLower(*format) {
+	case "json":
+		err = report.writeJSON(os.Stdout)
+	case "csv":
+		err = report.writeCSV(os.Stdout)
+	default:
+		fmt.Fprintln(os.Stderr, "unknown format", *format)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func (game *Game) calcStats() {
+	fmt.Println("=====================Stats=====================")
+	category := ""
+	for _, s := range game.collectStats() {
+		if s.Category != category {
+			category = s.Category
+			fmt.Printf("%s%s%s\n", strings.Repeat("-", 21), strings.ToUpper(category), strings.Repeat("-", 21))
+		}
+		fmt.Printf("%-13s%6d/%-7d = %9.6f%%\n", s.Name, s.Count, s.Total, s.percent())
+	}
+	fmt.Println("======================END======================")
+	pause()
+}
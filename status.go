@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Status effects. The player and enemies each carry a list of statuses that
+// last a number of their own turns. Some tick, doing damage or healing every
+// turn, the rest change stats for as long as they last. Moves, items and the
+// shrines of Mystical Rooms put them on.
+
+type StatusType int8
+
+const (
+	STATUS_POISON       StatusType = iota // takes power damage at the start of every turn
+	STATUS_BLEED        StatusType = iota // takes power damage at the start of every turn
+	STATUS_STUN         StatusType = iota // skips its turns
+	STATUS_REGEN        StatusType = iota // heals power at the end of every turn
+	STATUS_WEAKENED     StatusType = iota // strength is lowered by a power share
+	STATUS_SHIELDED     StatusType = iota // defense is raised by power
+	STATUS_ARMOR_BROKEN StatusType = iota // defense is lowered by power
+)
+
+// StackRule is what happens when a status is put on someone who already has it.
+type StackRule int8
+
+const (
+	STACK_REFRESH   StackRule = iota // keep the stronger power and the longer duration
+	STACK_INTENSITY StackRule = iota // add the powers up and keep the longer duration
+	STACK_DURATION  StackRule = iota // add the durations up and keep the stronger power
+)
+
+// TickTiming is when in its bearer's turn a status does its thing and counts
+// down. Statuses that only change stats count down at the start of the turn.
+type TickTiming int8
+
+const (
+	TICK_START TickTiming = iota
+	TICK_END   TickTiming = iota
+)
+
+type statusRule struct {
+	name    string
+	stack   StackRule
+	timing  TickTiming
+	harmful bool // harmful statuses go on the target, the others on the user
+}
+
+var statusRules = map[StatusType]statusRule{
+	STATUS_POISON:       {"Poison", STACK_INTENSITY, TICK_START, true},
+	STATUS_BLEED:        {"Bleed", STACK_REFRESH, TICK_START, true},
+	STATUS_STUN:         {"Stun", STACK_REFRESH, TICK_START, true},
+	STATUS_REGEN:        {"Regen", STACK_REFRESH, TICK_END, false},
+	STATUS_WEAKENED:     {"Weakened", STACK_REFRESH, TICK_START, true},
+	STATUS_SHIELDED:     {"Shielded", STACK_DURATION, TICK_START, false},
+	STATUS_ARMOR_BROKEN: {"Armor Broken", STACK_INTENSITY, TICK_START, true},
+}
+
+func getAllStatusTypes() [7]StatusType {
+	return [7]StatusType{STATUS_POISON, STATUS_BLEED, STATUS_STUN, STATUS_REGEN, STATUS_WEAKENED, STATUS_SHIELDED, STATUS_ARMOR_BROKEN}
+}
+
+func getStringFromStatusType(sType StatusType) string {
+	if rule, ok := statusRules[sType]; ok {
+		return rule.name
+	}
+	return "INVALID"
+}
+
+func getStatusTypeFromString(name string) StatusType {
+	for _, sType := range getAllStatusTypes() {
+		if getStringFromStatusType(sType) == name {
+			return sType
+		}
+	}
+	return -1
+}
+
+func isHarmful(sType StatusType) bool {
+	return statusRules[sType].harmful
+}
+
+type Status struct {
+	sType StatusType
+	power float64
+	turns int // turns of its bearer left
+}
+
+func (s *Status) describe() string {
+	text := getStringFromStatusType(s.sType)
+	if s.sType != STATUS_STUN {
+		text += fmt.Sprintf(" %.2f", s.power)
+	}
+	if s.turns == 1 {
+		return text + " (1 turn)"
+	}
+	return text + fmt.Sprintf(" (%d turns)", s.turns)
+}
+
+// Statuses is everything affecting the player or an enemy, at most one Status
+// per StatusType.
+type Statuses []*Status
+
+func (s Statuses) get(sType StatusType) *Status {
+	for _, status := range s {
+		if status.sType == sType {
+			return status
+		}
+	}
+	return nil
+}
+
+func (s Statuses) has(sType StatusType) bool {
+	return s.get(sType) != nil
+}
+
+// power is the power of the status, 0 if there is none.
+func (s Statuses) power(sType StatusType) float64 {
+	if status := s.get(sType); status != nil {
+		return status.power
+	}
+	return 0
+}
+
+// strengthScale is what the bearer's strength is multiplied by.
+func (s Statuses) strengthScale() float64 {
+	return math.Max(0, 1-s.power(STATUS_WEAKENED))
+}
+
+// defenseBonus is added to the bearer's defense, it is below zero if their
+// armor is broken more than they are shielded.
+func (s Statuses) defenseBonus() float64 {
+	return s.power(STATUS_SHIELDED) - s.power(STATUS_ARMOR_BROKEN)
+}
+
+// describe lists the statuses for menus, or returns "" if there are none.
+func (s Statuses) describe() string {
+	names := make([]string, len(s))
+	for i, status := range s {
+		names[i] = status.describe()
+	}
+	return strings.Join(names, ", ")
+}
+
+// add puts a status on who, stacking it with the one they already have by the
+// rules of its type. who is "You" or "The <enemy name>".
+func (s *Statuses) add(sType StatusType, power float64, turns int, who string, res *Result) {
+	if turns < 1 {
+		return
+	}
+	status := s.get(sType)
+	if status == nil {
+		status = &Status{sType, power, turns}
+		*s = append(*s, status)
+	} else {
+		switch statusRules[sType].stack {
+		case STACK_REFRESH:
+			status.power = math.Max(status.power, power)
+			status.turns = maxInt(status.turns, turns)
+		case STACK_INTENSITY:
+			status.power += power
+			status.turns = maxInt(status.turns, turns)
+		case STACK_DURATION:
+			status.power = math.Max(status.power, power)
+			status.turns += turns
+		}
+	}
+	res.addEvent(EventStatusApplied, float64(status.turns), "%s on %s.", status.describe(), objectForm(who))
+}
+
+// tick runs the statuses with the given timing on who, whose health is at
+// health, and counts them down. It returns true if a stun takes the turn.
+func (s *Statuses) tick(timing TickTiming, who string, health *float64, maxHealth float64, res *Result) (stunned bool) {
+	for _, status := range *s {
+		if statusRules[status.sType].timing != timing {
+			continue
+		}
+		switch status.sType {
+		case STATUS_POISON, STATUS_BLEED:
+			*health -= status.power
+			res.addEvent(EventStatusDamage, status.power, "%s took %.2f %s damage.", who, status.power, getStringFromStatusType(status.sType))
+		case STATUS_REGEN:
+			healed := math.Max(0, math.Min(status.power, maxHealth-*health))
+			*health += healed
+			if healed > 0 {
+				res.addEvent(EventStatusHealed, healed, "%s regenerated %.2f health.", who, healed)
+			}
+		case STATUS_STUN:
+			stunned = true
+			res.addEvent(EventStunned, 0, "%s cannot act while stunned.", who)
+		}
+	}
+
+	left := (*s)[:0]
+	for _, status := range *s {
+		if statusRules[status.sType].timing == timing {
+			status.turns--
+		}
+		if status.turns > 0 {
+			left = append(left, status)
+		} else {
+			res.addEvent(EventStatusExpired, 0, "%s on %s wore off.", getStringFromStatusType(status.sType), objectForm(who))
+		}
+	}
+	*s = left
+	return stunned
+}
+
+// objectForm turns "You" and "The Peon" into "you" and "the Peon".
+func objectForm(who string) string {
+	if who == "" {
+		return who
+	}
+	return strings.ToLower(who[:1]) + who[1:]
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// startTurn runs the player's statuses at the start of their turn. A stunned
+// player loses the turn and the enemies go again.
+func (p *Player) startTurn(res *Result) {
+	for p.state != Dead {
+		stunned := p.statuses.tick(TICK_START, "You", &p.health, p.getMaxHealth(), res)
+		if p.checkDied(res) || !stunned {
+			return
+		}
+		p.endTurn(res)
+		if p.state != Fighting {
+			return
+		}
+		p.enemyTurn(res)
+	}
+}
+
+// endTurn runs the player's statuses at the end of their turn.
+func (p *Player) endTurn(res *Result) {
+	p.statuses.tick(TICK_END, "You", &p.health, p.getMaxHealth(), res)
+	p.checkDied(res)
+}
+
+// checkDied kills the player off if their statuses took the last of their
+// health.
+func (p *Player) checkDied(res *Result) bool {
+	if p.state == Dead {
+		return true
+	}
+	if p.health > 0 {
+		return false
+	}
+	p.state = Dead
+	res.addEvent(EventPlayerDied, 0, "You succumbed to your wounds.")
+	return true
+}
+
+func (e *Enemy) getWho() string {
+	return "The " + getEnemyNameFromType(e.eType)
+}
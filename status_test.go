@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatusTick(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     Status
+		timing     TickTiming
+		health     float64 // out of 100, 90 before the tick
+		stunned    bool
+		turnsLeft  int // 0 if the status wore off
+		wantEvents []EventType
+	}{
+		{"poison", Status{STATUS_POISON, 5, 2}, TICK_START, 85, false, 1, []EventType{EventStatusDamage}},
+		{"bleed wears off", Status{STATUS_BLEED, 5, 1}, TICK_START, 85, false, 0, []EventType{EventStatusDamage, EventStatusExpired}},
+		{"stun", Status{STATUS_STUN, 0, 2}, TICK_START, 90, true, 1, []EventType{EventStunned}},
+		{"regen", Status{STATUS_REGEN, 5, 2}, TICK_END, 95, false, 1, []EventType{EventStatusHealed}},
+		{"regen past max health", Status{STATUS_REGEN, 50, 2}, TICK_END, 100, false, 1, []EventType{EventStatusHealed}},
+		{"shielded only counts down", Status{STATUS_SHIELDED, 5, 2}, TICK_START, 90, false, 1, nil},
+		{"poison waits for the start", Status{STATUS_POISON, 5, 2}, TICK_END, 90, false, 2, nil},
+		{"regen waits for the end", Status{STATUS_REGEN, 5, 2}, TICK_START, 90, false, 2, nil},
+	}
+	for _, test := range tests {
+		status := test.status
+		statuses := Statuses{&status}
+		health := 90.0
+		res := &Result{}
+		stunned := statuses.tick(test.timing, "You", &health, 100, res)
+		if health != test.health || stunned != test.stunned {
+			t.Errorf("%s: got health %v and stunned %v, want %v and %v", test.name, health, stunned, test.health, test.stunned)
+		}
+		turnsLeft := 0
+		if left := statuses.get(test.status.sType); left != nil {
+			turnsLeft = left.turns
+		}
+		if turnsLeft != test.turnsLeft {
+			t.Errorf("%s: %d turns left, want %d", test.name, turnsLeft, test.turnsLeft)
+		}
+		if got := eventTypes(res); !reflect.DeepEqual(got, test.wantEvents) {
+			t.Errorf("%s: got events %v, want %v", test.name, got, test.wantEvents)
+		}
+	}
+}
+
+func TestStatusStacking(t *testing.T) {
+	tests := []struct {
+		name  string
+		sType StatusType
+		power float64
+		turns int
+	}{
+		{"refresh keeps the stronger and the longer", STATUS_BLEED, 5, 3},
+		{"intensity adds the powers up", STATUS_POISON, 7, 3},
+		{"duration adds the turns up", STATUS_SHIELDED, 5, 5},
+	}
+	for _, test := range tests {
+		var statuses Statuses
+		statuses.add(test.sType, 2, 3, "You", &Result{})
+		statuses.add(test.sType, 5, 2, "You", &Result{})
+		if len(statuses) != 1 {
+			t.Fatalf("%s: got %d statuses, want them stacked into 1", test.name, len(statuses))
+		}
+		if statuses[0].power != test.power || statuses[0].turns != test.turns {
+			t.Errorf("%s: got power %v for %d turns, want %v for %d", test.name, statuses[0].power, statuses[0].turns, test.power, test.turns)
+		}
+	}
+}
+
+func TestStunnedPlayerLosesTheTurn(t *testing.T) {
+	game := newTestGame(t)
+	clearRoom(game)
+	p := game.player
+	addEnemy(game, PEON, 1000)
+	p.statuses.add(STATUS_STUN, 0, 1, "You", &Result{})
+
+	res := &Result{}
+	p.startTurn(res)
+	if !hasEvent(res, EventStunned) || !hasEvent(res, EventStatusExpired) {
+		t.Errorf("got events %v, want EventStunned and EventStatusExpired", eventTypes(res))
+	}
+	if p.statuses.has(STATUS_STUN) {
+		t.Error("the stun did not wear off")
+	}
+}